@@ -0,0 +1,131 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+// Condition types set in the status of the dataplane resources
+const (
+	// ReadyCondition is True when all conditions required by the readiness
+	// policy of the role are met
+	ReadyCondition = "Ready"
+
+	// NodeMaintenanceCondition is True while the node is in maintenance
+	NodeMaintenanceCondition = "Maintenance"
+
+	// AnsibleCredentialsCondition is True when the credentials of the
+	// ansible user passed the preflight checks
+	AnsibleCredentialsCondition = "AnsibleCredentials"
+
+	// KubernetesNodeLinkedCondition is True when the node is linked to a
+	// schedulable Kubernetes Node
+	KubernetesNodeLinkedCondition = "KubernetesNodeLinked"
+
+	// PhysnetsConsistentCondition is True when all nodes of a role map their
+	// physical networks to the same bridges and VLAN ranges
+	PhysnetsConsistentCondition = "PhysnetsConsistent"
+
+	// GlobalVarsCondition is True when all OpenStackDataPlaneGlobalVars
+	// referenced by the role of the node exist
+	GlobalVarsCondition = "GlobalVars"
+
+	// InventoryReadyCondition is True once the inventory of the node has been
+	// generated, i.e. the node has been handed over for deployment. The
+	// host name and networks of the node are immutable from then on.
+	InventoryReadyCondition = "InventoryReady"
+)
+
+// Condition reasons. These are part of the API: automation may branch on
+// them, so existing values must not be renamed and a reason keeps the same
+// meaning wherever it is used.
+const (
+	// MaintenanceEnabledReason - the node is in maintenance
+	MaintenanceEnabledReason = "MaintenanceEnabled"
+
+	// MaintenanceClearedReason - the node left maintenance and its
+	// configuration may be stale
+	MaintenanceClearedReason = "MaintenanceCleared"
+
+	// CredentialsValidReason - the ansible credentials passed the preflight
+	CredentialsValidReason = "CredentialsValid"
+
+	// CredentialsInvalidReason - the ansible credentials of one or more
+	// nodes failed the preflight
+	CredentialsInvalidReason = "CredentialsInvalid"
+
+	// SecretNotFoundReason - a referenced Secret does not exist
+	SecretNotFoundReason = "SecretNotFound"
+
+	// AnsibleUserMissingReason - credentials were given without an ansible
+	// user
+	AnsibleUserMissingReason = "AnsibleUserMissing"
+
+	// PasswordMissingReason - the password Secret has no or an empty
+	// password key
+	PasswordMissingReason = "PasswordMissing"
+
+	// KubernetesNodeNotFoundReason - no Kubernetes Node matches the node
+	KubernetesNodeNotFoundReason = "KubernetesNodeNotFound"
+
+	// KubernetesNodeLinkedReason - the node is linked to a schedulable
+	// Kubernetes Node
+	KubernetesNodeLinkedReason = "KubernetesNodeLinked"
+
+	// KubernetesNodeUnschedulableReason - the linked Kubernetes Node is
+	// cordoned
+	KubernetesNodeUnschedulableReason = "KubernetesNodeUnschedulable"
+
+	// PhysnetsMatchReason - all nodes share the same physnet mappings
+	PhysnetsMatchReason = "PhysnetsMatch"
+
+	// PhysnetsMismatchReason - nodes differ in their physnet mappings
+	PhysnetsMismatchReason = "PhysnetsMismatch"
+
+	// GlobalVarsResolvedReason - all referenced global vars exist
+	GlobalVarsResolvedReason = "GlobalVarsResolved"
+
+	// GlobalVarsNotFoundReason - a referenced OpenStackDataPlaneGlobalVars
+	// does not exist
+	GlobalVarsNotFoundReason = "GlobalVarsNotFound"
+
+	// InventoryGeneratedReason - the inventory of the node was generated
+	InventoryGeneratedReason = "InventoryGenerated"
+
+	// ReadyReason - all conditions required to be ready are met
+	ReadyReason = "Ready"
+
+	// RequiredConditionsNotMetReason - a condition required to be ready is
+	// False
+	RequiredConditionsNotMetReason = "RequiredConditionsNotMet"
+)
+
+// DefaultReadinessConditions are the conditions gating Ready when the
+// readiness policy of a role does not list any
+var DefaultReadinessConditions = []string{
+	AnsibleCredentialsCondition,
+	GlobalVarsCondition,
+	PhysnetsConsistentCondition,
+	KubernetesNodeLinkedCondition,
+}
+
+// ReadinessConditions are the condition types a readiness policy may require.
+// Ready itself is excluded, as the role would read back its own result.
+var ReadinessConditions = []string{
+	AnsibleCredentialsCondition,
+	GlobalVarsCondition,
+	PhysnetsConsistentCondition,
+	KubernetesNodeLinkedCondition,
+	InventoryReadyCondition,
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultNodeTemplateName is the name of the OpenStackDataPlaneDefaultNodeTemplate
+// applying to the nodes of its namespace
+const DefaultNodeTemplateName = "default"
+
+// OpenStackDataPlaneDefaultNodeTemplateSpec defines the desired state of OpenStackDataPlaneDefaultNodeTemplate
+type OpenStackDataPlaneDefaultNodeTemplateSpec struct {
+	// +kubebuilder:validation:Optional
+	// NodeTemplate - Defaults for all nodes of the namespace, layered under
+	// the node template of their role and the node itself. ansibleUser,
+	// ansiblePasswordSecret, ansiblePort, managementNetwork, realtime,
+	// storage, nfsMounts, kernel, physnets and containerImages are taken into
+	// account; sections such as realtime are used as a whole
+	NodeTemplate NodeSection `json:"nodeTemplate,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// OpenStackDataPlaneDefaultNodeTemplate is the Schema for the openstackdataplanedefaultnodetemplates API.
+// Only the object named "default" is used.
+type OpenStackDataPlaneDefaultNodeTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec OpenStackDataPlaneDefaultNodeTemplateSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// OpenStackDataPlaneDefaultNodeTemplateList contains a list of OpenStackDataPlaneDefaultNodeTemplate
+type OpenStackDataPlaneDefaultNodeTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OpenStackDataPlaneDefaultNodeTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OpenStackDataPlaneDefaultNodeTemplate{}, &OpenStackDataPlaneDefaultNodeTemplateList{})
+}
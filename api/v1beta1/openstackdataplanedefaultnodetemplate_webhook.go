@@ -0,0 +1,75 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var openstackdataplanedefaultnodetemplatelog = logf.Log.WithName("openstackdataplanedefaultnodetemplate-resource")
+
+// SetupWebhookWithManager sets up the webhook with the Manager
+func (r *OpenStackDataPlaneDefaultNodeTemplate) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-core-openstack-org-v1beta1-openstackdataplanedefaultnodetemplate,mutating=false,failurePolicy=fail,sideEffects=None,groups=core.openstack.org,resources=openstackdataplanedefaultnodetemplates,verbs=create;update,versions=v1beta1,name=vopenstackdataplanedefaultnodetemplate.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &OpenStackDataPlaneDefaultNodeTemplate{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *OpenStackDataPlaneDefaultNodeTemplate) ValidateCreate() error {
+	openstackdataplanedefaultnodetemplatelog.Info("validate create", "name", r.Name)
+
+	return r.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *OpenStackDataPlaneDefaultNodeTemplate) ValidateUpdate(old runtime.Object) error {
+	openstackdataplanedefaultnodetemplatelog.Info("validate update", "name", r.Name)
+
+	return r.validate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *OpenStackDataPlaneDefaultNodeTemplate) ValidateDelete() error {
+	openstackdataplanedefaultnodetemplatelog.Info("validate delete", "name", r.Name)
+
+	return nil
+}
+
+// validate checks the default node template, whose settings are layered
+// under those of the roles and nodes of the namespace
+func (r *OpenStackDataPlaneDefaultNodeTemplate) validate() error {
+	allErrs := validateNode(&r.Spec.NodeTemplate, field.NewPath("spec", "nodeTemplate"))
+	if len(allErrs) != 0 {
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: GroupVersion.Group, Kind: "OpenStackDataPlaneDefaultNodeTemplate"},
+			r.Name, allErrs)
+	}
+
+	return nil
+}
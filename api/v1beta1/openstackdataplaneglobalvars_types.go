@@ -0,0 +1,54 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OpenStackDataPlaneGlobalVarsSpec defines the desired state of OpenStackDataPlaneGlobalVars
+type OpenStackDataPlaneGlobalVarsSpec struct {
+	// +kubebuilder:validation:Optional
+	// Vars - Ansible variables shared by all nodes of the roles referencing
+	// this object, e.g. the cloud domain, NTP servers or container registries.
+	// Values can be any YAML value, including lists and maps
+	Vars map[string]apiextensionsv1.JSON `json:"vars,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// OpenStackDataPlaneGlobalVars is the Schema for the openstackdataplaneglobalvars API
+type OpenStackDataPlaneGlobalVars struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec OpenStackDataPlaneGlobalVarsSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// OpenStackDataPlaneGlobalVarsList contains a list of OpenStackDataPlaneGlobalVars
+type OpenStackDataPlaneGlobalVarsList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OpenStackDataPlaneGlobalVars `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OpenStackDataPlaneGlobalVars{}, &OpenStackDataPlaneGlobalVarsList{})
+}
@@ -60,6 +60,15 @@ type NodeSection struct {
 	// AnsibleUser SSH user for Ansible connection
 	AnsibleUser string `json:"ansibleUser,omitempty"`
 
+	// +kubebuilder:validation:Optional
+	// AnsiblePasswordSecret Name of a Secret holding the SSH password of the
+	// AnsibleUser under the "password" key. The password is passed to ansible
+	// as ansible_password and ansible_become_password through the
+	// dataplanenode-<name>-credentials Secret, which holds an inventory to use
+	// along with the generated one. The preflight checks only that the Secret
+	// and the password exist; they do not log into the node or test sudo
+	AnsiblePasswordSecret string `json:"ansiblePasswordSecret,omitempty"`
+
 	// +kubebuilder:validation:Optional
 	// AnsibleHost SSH host for Ansible connection
 	AnsibleHost string `json:"ansibleHost,omitempty"`
@@ -67,6 +76,164 @@ type NodeSection struct {
 	// +kubebuilder:validation:Optional
 	// AnsiblePort SSH port for Ansible connection
 	AnsiblePort int `json:"ansiblePort,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Maintenance - Whether the node is in maintenance. Nodes in maintenance
+	// are excluded from provisioning and configuration, and left out of the
+	// inventory and peers of their role, until it is cleared
+	Maintenance bool `json:"maintenance,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Realtime - Realtime/low-latency compute profile of the node
+	Realtime *RealtimeSection `json:"realtime,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Storage - Storage connectivity (multipath, iSCSI, FC) of the node
+	Storage *StorageSection `json:"storage,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// NFSMounts - NFS shares to mount on the node, e.g. for the Nova instances
+	// directory or the Glance image cache
+	NFSMounts []NFSMountSection `json:"nfsMounts,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// LinkKubernetesNode - Whether the node is also a worker of this cluster.
+	// When set, the matching Kubernetes Node is looked up by address, labelled
+	// with the dataplane node and role, and network configuration is held
+	// back while it is unschedulable. The labels are removed again when the
+	// setting is cleared, the address stops matching or the node is deleted
+	LinkKubernetesNode bool `json:"linkKubernetesNode,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Kernel - sysctls and kernel modules to configure on the node
+	Kernel *KernelSection `json:"kernel,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Physnets - Neutron physical networks and the OVS bridges they map to
+	Physnets []PhysnetSection `json:"physnets,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// ContainerImages - EDPM container image variables, e.g.
+	// edpm_nova_compute_image, mapped to the image to use. Set on the node
+	// template of a role they apply to all of its nodes, and set on a node
+	// they override the role, e.g. to stage newer images on a canary node
+	ContainerImages map[string]string `json:"containerImages,omitempty"`
+}
+
+type RealtimeSection struct {
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=kernel-rt
+	// KernelPackage - realtime kernel package to boot the node with
+	KernelPackage string `json:"kernelPackage,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=realtime-virtual-host
+	// TunedProfile - tuned profile to apply
+	TunedProfile string `json:"tunedProfile,omitempty"`
+
+	// +kubebuilder:validation:Required
+	// IsolatedCPUs - CPU list (e.g. "2-19,22-39") isolated from the host for
+	// realtime workloads
+	IsolatedCPUs string `json:"isolatedCPUs"`
+
+	// +kubebuilder:validation:Required
+	// HousekeepingCPUs - CPU list reserved for host processes. Must not
+	// overlap with IsolatedCPUs. Interrupts are steered to these CPUs with
+	// the irqaffinity kernel argument
+	HousekeepingCPUs string `json:"housekeepingCPUs"`
+}
+
+type StorageSection struct {
+
+	// +kubebuilder:validation:Optional
+	// Multipath - Whether to configure and enable multipathd
+	Multipath bool `json:"multipath,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// ISCSI - Whether to configure and enable the iSCSI initiator
+	ISCSI bool `json:"iscsi,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// FibreChannel - Whether the node connects to Fibre Channel storage
+	FibreChannel bool `json:"fibreChannel,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Targets - Storage targets the node is expected to reach, as iSCSI
+	// portals (host:port) or FC target WWPNs (16 hex digits). They are
+	// informational: they are validated and rendered as edpm_storage_targets
+	// for custom playbooks, but no EDPM role consumes them and the operator
+	// does not check that the paths are up
+	Targets []string `json:"targets,omitempty"`
+}
+
+type PhysnetSection struct {
+
+	// +kubebuilder:validation:Required
+	// Name - Neutron physical network name, e.g. datacentre
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:Required
+	// Bridge - OVS bridge the physical network is mapped to, e.g. br-ex
+	Bridge string `json:"bridge"`
+
+	// +kubebuilder:validation:Optional
+	// Interface - Host interface plugged into the bridge
+	Interface string `json:"interface,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// VLANRanges - VLAN ID ranges available for tenant networks on the
+	// physical network, as "min:max"
+	VLANRanges []string `json:"vlanRanges,omitempty"`
+}
+
+type KernelSection struct {
+
+	// +kubebuilder:validation:Optional
+	// Sysctls - Kernel parameters to set, e.g. net.ipv4.ip_forward: "1".
+	// Names must be dotted parameter names and values must not be empty
+	Sysctls map[string]string `json:"sysctls,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Modules - Kernel modules to load or blacklist
+	Modules []KernelModuleSection `json:"modules,omitempty"`
+}
+
+type KernelModuleSection struct {
+
+	// +kubebuilder:validation:Required
+	// Name - Kernel module name
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:Optional
+	// Options - Module parameters, e.g. "nested=1"
+	Options string `json:"options,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Blacklist - Whether to prevent the module from being loaded instead of
+	// loading it
+	Blacklist bool `json:"blacklist,omitempty"`
+}
+
+type NFSMountSection struct {
+
+	// +kubebuilder:validation:Required
+	// Server - NFS server host name or IP address
+	Server string `json:"server"`
+
+	// +kubebuilder:validation:Required
+	// Export - Absolute path of the share exported by the server
+	Export string `json:"export"`
+
+	// +kubebuilder:validation:Required
+	// MountPoint - Absolute path the share is mounted on, e.g.
+	// /var/lib/nova/instances
+	MountPoint string `json:"mountPoint"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=defaults
+	// Options - Mount options
+	Options string `json:"options,omitempty"`
 }
 
 type NetworkConfigSection struct {
@@ -81,20 +248,49 @@ type NetworkConfigSection struct {
 type NetworksSection struct {
 
 	// +kubebuilder:validation:Optional
-	// Network - Network name to configure
+	// Network - Network name to configure, given under the template key. It
+	// must be set and be unique among the networks of the node
 	Network string `json:"template,omitempty"`
 
 	// +kubebuilder:validation:Optional
-	// FixedIP - Specific IP address to use for this network
+	// FixedIP - Specific IP address to use for this network. It may be set
+	// on a deployed node that had none, but not changed once set
 	FixedIP string `json:"fixedIP,omitempty"`
 }
 
 // OpenStackDataPlaneNodeStatus defines the observed state of OpenStackDataPlaneNode
 type OpenStackDataPlaneNodeStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// +operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors={"urn:alm:descriptor:io.kubernetes.conditions"}
+	// Conditions
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// KubernetesNode - Name of the Kubernetes Node linked to this node
+	KubernetesNode string `json:"kubernetesNode,omitempty"`
+
+	// AppliedDefaults - Fields of the node taken from the
+	// OpenStackDataPlaneDefaultNodeTemplate of the namespace, as neither the
+	// node nor the node template of its role set them
+	AppliedDefaults []string `json:"appliedDefaults,omitempty"`
 }
 
+const (
+	// KubernetesNodeLabel is set on linked Kubernetes Nodes to the name of
+	// the OpenStackDataPlaneNode
+	KubernetesNodeLabel = "dataplane.openstack.org/node"
+
+	// KubernetesRoleLabel is set on linked Kubernetes Nodes to the role of
+	// the OpenStackDataPlaneNode
+	KubernetesRoleLabel = "dataplane.openstack.org/role"
+
+	// InventoryNodeLabel is set on the generated inventory and credentials
+	// of a node to the name of the OpenStackDataPlaneNode
+	InventoryNodeLabel = "dataplane.openstack.org/inventory-node"
+
+	// InventoryRoleLabel is set on the generated inventory and credentials
+	// of a node to the role of the OpenStackDataPlaneNode
+	InventoryRoleLabel = "dataplane.openstack.org/inventory-role"
+)
+
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 
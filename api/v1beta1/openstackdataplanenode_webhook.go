@@ -0,0 +1,453 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var openstackdataplanenodelog = logf.Log.WithName("openstackdataplanenode-resource")
+
+// SetupWebhookWithManager sets up the webhook with the Manager
+func (r *OpenStackDataPlaneNode) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-core-openstack-org-v1beta1-openstackdataplanenode,mutating=false,failurePolicy=fail,sideEffects=None,groups=core.openstack.org,resources=openstackdataplanenodes,verbs=create;update,versions=v1beta1,name=vopenstackdataplanenode.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &OpenStackDataPlaneNode{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *OpenStackDataPlaneNode) ValidateCreate() error {
+	openstackdataplanenodelog.Info("validate create", "name", r.Name)
+
+	allErrs := validateNode(&r.Spec.Node, field.NewPath("spec", "node"))
+	if len(allErrs) != 0 {
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: GroupVersion.Group, Kind: "OpenStackDataPlaneNode"},
+			r.Name, allErrs)
+	}
+
+	return nil
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *OpenStackDataPlaneNode) ValidateUpdate(old runtime.Object) error {
+	openstackdataplanenodelog.Info("validate update", "name", r.Name)
+
+	oldNode, ok := old.(*OpenStackDataPlaneNode)
+	if !ok {
+		return apierrors.NewInternalError(
+			fmt.Errorf("expected an OpenStackDataPlaneNode object, got %T", old))
+	}
+
+	// Metadata only updates, e.g. removing a finalizer from a node being
+	// deleted, must not be blocked by checks added after the node was created
+	if r.DeletionTimestamp != nil || equality.Semantic.DeepEqual(r.Spec, oldNode.Spec) {
+		return nil
+	}
+
+	allErrs := validateNode(&r.Spec.Node, field.NewPath("spec", "node"))
+	if meta.IsStatusConditionTrue(oldNode.Status.Conditions, InventoryReadyCondition) {
+		allErrs = append(allErrs, validateNodeImmutable(
+			&r.Spec.Node, &oldNode.Spec.Node, field.NewPath("spec", "node"))...)
+	}
+	if len(allErrs) != 0 {
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: GroupVersion.Group, Kind: "OpenStackDataPlaneNode"},
+			r.Name, allErrs)
+	}
+
+	return nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *OpenStackDataPlaneNode) ValidateDelete() error {
+	openstackdataplanenodelog.Info("validate delete", "name", r.Name)
+
+	return nil
+}
+
+// validateNode checks the node for settings that conflict with each other
+func validateNode(node *NodeSection, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if node.Realtime != nil {
+		allErrs = append(allErrs, validateRealtime(node.Realtime, path.Child("realtime"))...)
+	}
+
+	if node.Storage != nil {
+		allErrs = append(allErrs, validateStorage(node.Storage, path.Child("storage"))...)
+	}
+
+	allErrs = append(allErrs, validateNetworks(node.Networks, path.Child("networks"))...)
+
+	allErrs = append(allErrs, validateNFSMounts(node.NFSMounts, path.Child("nfsMounts"))...)
+
+	if node.Kernel != nil {
+		allErrs = append(allErrs, validateKernel(node.Kernel, path.Child("kernel"))...)
+	}
+
+	allErrs = append(allErrs, validatePhysnets(node.Physnets, path.Child("physnets"))...)
+
+	allErrs = append(allErrs, validateContainerImages(node.ContainerImages, path.Child("containerImages"))...)
+
+	return allErrs
+}
+
+// validatePhysnets checks that physical networks and bridges are unique on
+// the node and that the VLAN ranges are valid
+func validatePhysnets(physnets []PhysnetSection, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	names := make(map[string]bool, len(physnets))
+	bridges := make(map[string]string, len(physnets))
+	for idx, physnet := range physnets {
+		physnetPath := path.Index(idx)
+		if names[physnet.Name] {
+			allErrs = append(allErrs, field.Duplicate(physnetPath.Child("name"), physnet.Name))
+		}
+		names[physnet.Name] = true
+
+		if other, found := bridges[physnet.Bridge]; found {
+			allErrs = append(allErrs, field.Invalid(physnetPath.Child("bridge"), physnet.Bridge,
+				fmt.Sprintf("bridge is already mapped to physical network %s", other)))
+		}
+		bridges[physnet.Bridge] = physnet.Name
+
+		for rangeIdx, vlanRange := range physnet.VLANRanges {
+			if err := validateVLANRange(vlanRange); err != nil {
+				allErrs = append(allErrs, field.Invalid(physnetPath.Child("vlanRanges").Index(rangeIdx), vlanRange, err.Error()))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// validateVLANRange checks a "min:max" VLAN ID range
+func validateVLANRange(vlanRange string) error {
+	bounds := strings.Split(vlanRange, ":")
+	if len(bounds) != 2 {
+		return fmt.Errorf("VLAN range must be in the min:max format")
+	}
+	first, err := strconv.Atoi(bounds[0])
+	if err != nil {
+		return fmt.Errorf("invalid VLAN ID %q", bounds[0])
+	}
+	last, err := strconv.Atoi(bounds[1])
+	if err != nil {
+		return fmt.Errorf("invalid VLAN ID %q", bounds[1])
+	}
+	if first < 1 || last > 4094 || first > last {
+		return fmt.Errorf("VLAN range must be within 1:4094 with min not greater than max")
+	}
+
+	return nil
+}
+
+// requiredKernelModules are kernel modules the built-in services depend on,
+// keyed by module name with the reason they are needed
+var requiredKernelModules = map[string]string{
+	"openvswitch":  "Open vSwitch networking requires it",
+	"br_netfilter": "bridge traffic filtering of the instance networks requires it",
+}
+
+// sysctlRegexp matches dotted kernel parameter names such as
+// net.ipv4.ip_forward or net.ipv4.conf.eth0.rp_filter
+var sysctlRegexp = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-zA-Z0-9_-]+)+$`)
+
+// validateKernel checks the kernel parameter names, that kernel modules are
+// listed once and that none of the modules required by the built-in services
+// is blacklisted
+func validateKernel(kernel *KernelSection, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	names := make([]string, 0, len(kernel.Sysctls))
+	for name := range kernel.Sysctls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !sysctlRegexp.MatchString(name) {
+			allErrs = append(allErrs, field.Invalid(path.Child("sysctls").Key(name), name,
+				"sysctl must be a dotted kernel parameter name, e.g. net.ipv4.ip_forward"))
+		} else if kernel.Sysctls[name] == "" {
+			allErrs = append(allErrs, field.Required(path.Child("sysctls").Key(name), "sysctl value must be set"))
+		}
+	}
+
+	modules := make(map[string]bool, len(kernel.Modules))
+	for idx, module := range kernel.Modules {
+		modulePath := path.Child("modules").Index(idx)
+		if modules[module.Name] {
+			allErrs = append(allErrs, field.Duplicate(modulePath.Child("name"), module.Name))
+		}
+		modules[module.Name] = true
+
+		if reason, required := requiredKernelModules[module.Name]; required && module.Blacklist {
+			allErrs = append(allErrs, field.Forbidden(modulePath.Child("blacklist"),
+				fmt.Sprintf("kernel module %s can not be blacklisted: %s", module.Name, reason)))
+		}
+		if module.Blacklist && module.Options != "" {
+			allErrs = append(allErrs, field.Invalid(modulePath.Child("options"), module.Options,
+				"options can not be set on a blacklisted module"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateNFSMounts checks that NFS mounts use absolute paths and that no two
+// shares are mounted on the same mount point
+func validateNFSMounts(mounts []NFSMountSection, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	mountPoints := make(map[string]bool, len(mounts))
+	for idx, mount := range mounts {
+		if mount.Server == "" {
+			allErrs = append(allErrs, field.Required(path.Index(idx).Child("server"), "NFS server must be set"))
+		}
+		if !filepath.IsAbs(mount.Export) {
+			allErrs = append(allErrs, field.Invalid(path.Index(idx).Child("export"), mount.Export,
+				"export must be an absolute path"))
+		}
+		if !filepath.IsAbs(mount.MountPoint) {
+			allErrs = append(allErrs, field.Invalid(path.Index(idx).Child("mountPoint"), mount.MountPoint,
+				"mountPoint must be an absolute path"))
+			continue
+		}
+		mountPoint := filepath.Clean(mount.MountPoint)
+		if mountPoints[mountPoint] {
+			allErrs = append(allErrs, field.Duplicate(path.Index(idx).Child("mountPoint"), mount.MountPoint))
+		}
+		mountPoints[mountPoint] = true
+	}
+
+	return allErrs
+}
+
+// imageVarRegexp matches the name of an EDPM container image variable
+var imageVarRegexp = regexp.MustCompile(`^edpm_[a-z0-9_]+_image$`)
+
+// validateContainerImages checks that only EDPM image variables are set and
+// that none of them is empty
+func validateContainerImages(images map[string]string, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	names := make([]string, 0, len(images))
+	for name := range images {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		image := images[name]
+		if !imageVarRegexp.MatchString(name) {
+			allErrs = append(allErrs, field.Invalid(path.Key(name), name,
+				"must be an EDPM container image variable, edpm_<name>_image"))
+		}
+		if image == "" {
+			allErrs = append(allErrs, field.Required(path.Key(name), "image must not be empty"))
+		}
+	}
+
+	return allErrs
+}
+
+// wwpnRegexp matches a Fibre Channel WWPN, with or without colons
+var wwpnRegexp = regexp.MustCompile(`^([0-9a-fA-F]{2}:){7}[0-9a-fA-F]{2}$|^[0-9a-fA-F]{16}$`)
+
+// validateStorage checks that every storage target matches an enabled
+// storage protocol
+func validateStorage(storage *StorageSection, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for idx, target := range storage.Targets {
+		switch {
+		case wwpnRegexp.MatchString(target):
+			if !storage.FibreChannel {
+				allErrs = append(allErrs, field.Invalid(path.Child("targets").Index(idx), target,
+					"target is a Fibre Channel WWPN but fibreChannel is not enabled"))
+			}
+		default:
+			if _, _, err := net.SplitHostPort(target); err != nil {
+				allErrs = append(allErrs, field.Invalid(path.Child("targets").Index(idx), target,
+					"target must be an iSCSI portal (host:port) or a Fibre Channel WWPN"))
+			} else if !storage.ISCSI {
+				allErrs = append(allErrs, field.Invalid(path.Child("targets").Index(idx), target,
+					"target is an iSCSI portal but iscsi is not enabled"))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// validateRealtime checks that the CPU lists of the realtime profile are
+// well formed and that no CPU is both isolated and used for housekeeping
+func validateRealtime(realtime *RealtimeSection, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	isolated, err := parseCPUList(realtime.IsolatedCPUs)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(path.Child("isolatedCPUs"), realtime.IsolatedCPUs, err.Error()))
+	}
+	housekeeping, err := parseCPUList(realtime.HousekeepingCPUs)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(path.Child("housekeepingCPUs"), realtime.HousekeepingCPUs, err.Error()))
+	}
+	if len(allErrs) != 0 {
+		return allErrs
+	}
+
+	var overlap []int
+	for cpu := range isolated {
+		if housekeeping[cpu] {
+			overlap = append(overlap, cpu)
+		}
+	}
+	if len(overlap) != 0 {
+		sort.Ints(overlap)
+		cpus := make([]string, len(overlap))
+		for i, cpu := range overlap {
+			cpus[i] = strconv.Itoa(cpu)
+		}
+		allErrs = append(allErrs, field.Invalid(path.Child("isolatedCPUs"), realtime.IsolatedCPUs,
+			fmt.Sprintf("CPUs %s are also listed in housekeepingCPUs; a CPU can either be isolated or used for housekeeping",
+				strings.Join(cpus, ","))))
+	}
+
+	return allErrs
+}
+
+// maxCPUs bounds the CPU IDs accepted in CPU lists, well above the size of
+// any real host, so that a huge range can not exhaust the webhook memory
+const maxCPUs = 8192
+
+// parseCPUList parses a CPU list such as "0-3,8,10-11" into a set of CPUs
+func parseCPUList(cpuList string) (map[int]bool, error) {
+	cpus := make(map[int]bool)
+	if strings.TrimSpace(cpuList) == "" {
+		return nil, fmt.Errorf("CPU list must not be empty")
+	}
+
+	for _, part := range strings.Split(cpuList, ",") {
+		bounds := strings.SplitN(strings.TrimSpace(part), "-", 2)
+		first, err := strconv.Atoi(bounds[0])
+		if err != nil || first < 0 {
+			return nil, fmt.Errorf("invalid CPU %q in CPU list", part)
+		}
+		last := first
+		if len(bounds) == 2 {
+			last, err = strconv.Atoi(bounds[1])
+			if err != nil || last < first {
+				return nil, fmt.Errorf("invalid CPU range %q in CPU list", part)
+			}
+		}
+		if last >= maxCPUs {
+			return nil, fmt.Errorf("CPU %d in CPU list is out of range, CPU IDs must be lower than %d", last, maxCPUs)
+		}
+		for cpu := first; cpu <= last; cpu++ {
+			cpus[cpu] = true
+		}
+	}
+
+	return cpus, nil
+}
+
+// validateNetworks checks that every network of the node is named, under
+// the template key, and is attached only once
+func validateNetworks(networks []NetworksSection, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	names := make(map[string]bool, len(networks))
+	for idx, network := range networks {
+		if network.Network == "" {
+			allErrs = append(allErrs, field.Required(path.Index(idx).Child("template"),
+				"name of the network the node is attached to"))
+			continue
+		}
+		if names[network.Network] {
+			allErrs = append(allErrs, field.Duplicate(path.Index(idx).Child("template"), network.Network))
+		}
+		names[network.Network] = true
+	}
+
+	return allErrs
+}
+
+// validateNodeImmutable checks the fields of a node that can not change once
+// the node has been handed over for deployment. Networks may be added, but
+// networks already attached to the node can neither be removed nor have their
+// fixed IP changed. Networks are matched by name, or by position when the old
+// node has unnamed or duplicate networks, as stored before names were
+// required.
+func validateNodeImmutable(node *NodeSection, oldNode *NodeSection, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if oldNode.HostName != "" && node.HostName != oldNode.HostName {
+		allErrs = append(allErrs, field.Forbidden(path.Child("hostName"),
+			fmt.Sprintf("hostName can not be changed from %q to %q once the node is deployed; "+
+				"delete and re-create the node to rename it", oldNode.HostName, node.HostName)))
+	}
+
+	byPosition := len(validateNetworks(oldNode.Networks, path.Child("networks"))) != 0
+	networks := make(map[string]int, len(node.Networks))
+	for idx, network := range node.Networks {
+		networks[network.Network] = idx
+	}
+	for oldIdx, oldNetwork := range oldNode.Networks {
+		idx, found := oldIdx, oldIdx < len(node.Networks)
+		if !byPosition {
+			idx, found = networks[oldNetwork.Network]
+		}
+		if !found {
+			allErrs = append(allErrs, field.Forbidden(path.Child("networks").Index(oldIdx),
+				fmt.Sprintf("network %q can not be removed from a deployed node; "+
+					"only new networks may be added", oldNetwork.Network)))
+			continue
+		}
+		network := node.Networks[idx]
+		// Assigning a fixed IP to a network that had none is additive
+		if oldNetwork.FixedIP != "" && network.FixedIP != oldNetwork.FixedIP {
+			allErrs = append(allErrs, field.Forbidden(path.Child("networks").Index(idx).Child("fixedIP"),
+				fmt.Sprintf("fixedIP of network %q can not be changed from %q to %q on a deployed node",
+					oldNetwork.Network, oldNetwork.FixedIP, network.FixedIP)))
+		}
+	}
+
+	return allErrs
+}
@@ -0,0 +1,544 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func networks(nameIPs ...string) []NetworksSection {
+	var networks []NetworksSection
+	for i := 0; i < len(nameIPs); i += 2 {
+		networks = append(networks, NetworksSection{Network: nameIPs[i], FixedIP: nameIPs[i+1]})
+	}
+	return networks
+}
+
+func errorFields(errs field.ErrorList) []string {
+	var fields []string
+	for _, err := range errs {
+		fields = append(fields, string(err.Type)+" "+err.Field)
+	}
+	return fields
+}
+
+func expectErrors(t *testing.T, errs field.ErrorList, expected []string) {
+	t.Helper()
+	got := errorFields(errs)
+	if len(got) != len(expected) {
+		t.Fatalf("expected errors %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("expected errors %v, got %v", expected, got)
+		}
+	}
+}
+
+func TestValidateNetworks(t *testing.T) {
+	tests := []struct {
+		name     string
+		networks []NetworksSection
+		expected []string
+	}{
+		{
+			name:     "unique names",
+			networks: networks("ctlplane", "192.168.122.100", "internalapi", "172.17.0.100"),
+		},
+		{
+			name:     "empty name",
+			networks: networks("ctlplane", "192.168.122.100", "", "172.17.0.100"),
+			expected: []string{"FieldValueRequired networks[1].template"},
+		},
+		{
+			name:     "duplicate name",
+			networks: networks("ctlplane", "192.168.122.100", "ctlplane", "192.168.122.101"),
+			expected: []string{"FieldValueDuplicate networks[1].template"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expectErrors(t, validateNetworks(test.networks, field.NewPath("networks")), test.expected)
+		})
+	}
+}
+
+func TestValidateNodeImmutable(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      NodeSection
+		new      NodeSection
+		expected []string
+	}{
+		{
+			name: "unchanged networks with another field changed",
+			old:  NodeSection{HostName: "compute-0", Networks: networks("ctlplane", "192.168.122.100", "internalapi", "172.17.0.100")},
+			new:  NodeSection{HostName: "compute-0", Maintenance: true, Networks: networks("ctlplane", "192.168.122.100", "internalapi", "172.17.0.100")},
+		},
+		{
+			name: "reordered and added networks",
+			old:  NodeSection{Networks: networks("ctlplane", "192.168.122.100", "internalapi", "172.17.0.100")},
+			new:  NodeSection{Networks: networks("internalapi", "172.17.0.100", "tenant", "172.19.0.100", "ctlplane", "192.168.122.100")},
+		},
+		{
+			name:     "host name changed",
+			old:      NodeSection{HostName: "compute-0"},
+			new:      NodeSection{HostName: "compute-1"},
+			expected: []string{"FieldValueForbidden node.hostName"},
+		},
+		{
+			name: "host name set for the first time",
+			old:  NodeSection{},
+			new:  NodeSection{HostName: "compute-0"},
+		},
+		{
+			name:     "network removed",
+			old:      NodeSection{Networks: networks("ctlplane", "192.168.122.100", "internalapi", "172.17.0.100")},
+			new:      NodeSection{Networks: networks("ctlplane", "192.168.122.100")},
+			expected: []string{"FieldValueForbidden node.networks[1]"},
+		},
+		{
+			name:     "fixed IP changed",
+			old:      NodeSection{Networks: networks("ctlplane", "192.168.122.100", "internalapi", "172.17.0.100")},
+			new:      NodeSection{Networks: networks("internalapi", "172.17.0.101", "ctlplane", "192.168.122.100")},
+			expected: []string{"FieldValueForbidden node.networks[0].fixedIP"},
+		},
+		{
+			name: "fixed IP assigned",
+			old:  NodeSection{Networks: networks("ctlplane", "192.168.122.100", "internalapi", "")},
+			new:  NodeSection{Networks: networks("ctlplane", "192.168.122.100", "internalapi", "172.17.0.100")},
+		},
+		{
+			name:     "fixed IP cleared",
+			old:      NodeSection{Networks: networks("ctlplane", "192.168.122.100")},
+			new:      NodeSection{Networks: networks("ctlplane", "")},
+			expected: []string{"FieldValueForbidden node.networks[0].fixedIP"},
+		},
+		{
+			name: "unnamed networks compared by position",
+			old:  NodeSection{Networks: networks("", "192.168.122.100", "", "172.17.0.100")},
+			new:  NodeSection{Maintenance: true, Networks: networks("ctlplane", "192.168.122.100", "internalapi", "172.17.0.100")},
+		},
+		{
+			name:     "unnamed network fixed IP changed",
+			old:      NodeSection{Networks: networks("", "192.168.122.100", "", "172.17.0.100")},
+			new:      NodeSection{Networks: networks("ctlplane", "192.168.122.100", "internalapi", "172.17.0.101")},
+			expected: []string{"FieldValueForbidden node.networks[1].fixedIP"},
+		},
+		{
+			name:     "unnamed network removed",
+			old:      NodeSection{Networks: networks("", "192.168.122.100", "", "172.17.0.100")},
+			new:      NodeSection{Networks: networks("ctlplane", "192.168.122.100")},
+			expected: []string{"FieldValueForbidden node.networks[1]"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expectErrors(t, validateNodeImmutable(&test.new, &test.old, field.NewPath("node")), test.expected)
+		})
+	}
+}
+
+func TestValidateUpdateImmutableOnlyOnceDeployed(t *testing.T) {
+	oldNode := &OpenStackDataPlaneNode{
+		ObjectMeta: metav1.ObjectMeta{Name: "compute-0"},
+		Spec: OpenStackDataPlaneNodeSpec{
+			Node: NodeSection{HostName: "compute-0", Networks: networks("ctlplane", "192.168.122.100")},
+		},
+	}
+	newNode := oldNode.DeepCopy()
+	newNode.Spec.Node.HostName = "compute-1"
+	newNode.Spec.Node.Networks[0].FixedIP = "192.168.122.101"
+
+	if err := newNode.ValidateUpdate(oldNode); err != nil {
+		t.Fatalf("expected changes to a node not deployed yet to be accepted, got %v", err)
+	}
+
+	oldNode.Status.Conditions = []metav1.Condition{{
+		Type:   InventoryReadyCondition,
+		Status: metav1.ConditionTrue,
+		Reason: InventoryGeneratedReason,
+	}}
+	if err := newNode.ValidateUpdate(oldNode); err == nil {
+		t.Fatal("expected changes to a deployed node to be rejected")
+	}
+}
+
+func TestParseCPUList(t *testing.T) {
+	tests := []struct {
+		cpuList  string
+		expected []int
+		invalid  bool
+	}{
+		{cpuList: "0", expected: []int{0}},
+		{cpuList: "0-3,8,10-11", expected: []int{0, 1, 2, 3, 8, 10, 11}},
+		{cpuList: " 2 , 4-5 ", expected: []int{2, 4, 5}},
+		{cpuList: "8191", expected: []int{8191}},
+		{cpuList: "", invalid: true},
+		{cpuList: "a", invalid: true},
+		{cpuList: "-1", invalid: true},
+		{cpuList: "3-1", invalid: true},
+		{cpuList: "0-", invalid: true},
+		{cpuList: "1,,2", invalid: true},
+		{cpuList: "8192", invalid: true},
+		{cpuList: "0-2000000000", invalid: true},
+	}
+	for _, test := range tests {
+		t.Run(test.cpuList, func(t *testing.T) {
+			cpus, err := parseCPUList(test.cpuList)
+			if test.invalid {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", cpus)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(cpus) != len(test.expected) {
+				t.Fatalf("expected CPUs %v, got %v", test.expected, cpus)
+			}
+			for _, cpu := range test.expected {
+				if !cpus[cpu] {
+					t.Fatalf("expected CPUs %v, got %v", test.expected, cpus)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateRealtime(t *testing.T) {
+	tests := []struct {
+		name         string
+		isolated     string
+		housekeeping string
+		expected     []string
+	}{
+		{name: "disjoint", isolated: "2-7", housekeeping: "0-1"},
+		{name: "overlap", isolated: "1-7", housekeeping: "0-1", expected: []string{"FieldValueInvalid realtime.isolatedCPUs"}},
+		{name: "invalid isolated", isolated: "x", housekeeping: "0-1", expected: []string{"FieldValueInvalid realtime.isolatedCPUs"}},
+		{
+			name:         "both invalid",
+			isolated:     "0-9000",
+			housekeeping: "",
+			expected:     []string{"FieldValueInvalid realtime.isolatedCPUs", "FieldValueInvalid realtime.housekeepingCPUs"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			realtime := &RealtimeSection{IsolatedCPUs: test.isolated, HousekeepingCPUs: test.housekeeping}
+			expectErrors(t, validateRealtime(realtime, field.NewPath("realtime")), test.expected)
+		})
+	}
+}
+
+func TestValidateUpdateMetadataOnly(t *testing.T) {
+	// Duplicate networks are rejected today but may exist in objects
+	// created before the check was added
+	oldNode := &OpenStackDataPlaneNode{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "compute-0",
+			Finalizers: []string{"dataplane.openstack.org/kubernetes-node"},
+		},
+		Spec: OpenStackDataPlaneNodeSpec{
+			Node: NodeSection{Networks: networks("ctlplane", "192.168.122.100", "ctlplane", "192.168.122.101")},
+		},
+	}
+
+	newNode := oldNode.DeepCopy()
+	newNode.Finalizers = nil
+	if err := newNode.ValidateUpdate(oldNode); err != nil {
+		t.Fatalf("expected a metadata only update to be accepted, got %v", err)
+	}
+
+	newNode = oldNode.DeepCopy()
+	now := metav1.Now()
+	newNode.DeletionTimestamp = &now
+	newNode.Spec.Node.Maintenance = true
+	if err := newNode.ValidateUpdate(oldNode); err != nil {
+		t.Fatalf("expected an update of a node being deleted to be accepted, got %v", err)
+	}
+
+	newNode = oldNode.DeepCopy()
+	newNode.Spec.Node.Maintenance = true
+	if err := newNode.ValidateUpdate(oldNode); err == nil {
+		t.Fatal("expected a spec update to be validated")
+	}
+}
+
+func TestValidateVLANRange(t *testing.T) {
+	tests := []struct {
+		vlanRange string
+		valid     bool
+	}{
+		{vlanRange: "1:4094", valid: true},
+		{vlanRange: "100:100", valid: true},
+		{vlanRange: "0:100"},
+		{vlanRange: "1:4095"},
+		{vlanRange: "200:100"},
+		{vlanRange: "100"},
+		{vlanRange: "1:2:3"},
+		{vlanRange: "a:100"},
+		{vlanRange: "1:"},
+	}
+	for _, test := range tests {
+		t.Run(test.vlanRange, func(t *testing.T) {
+			err := validateVLANRange(test.vlanRange)
+			if test.valid && err != nil {
+				t.Errorf("expected %q to be valid, got %v", test.vlanRange, err)
+			}
+			if !test.valid && err == nil {
+				t.Errorf("expected %q to be rejected", test.vlanRange)
+			}
+		})
+	}
+}
+
+func TestValidatePhysnets(t *testing.T) {
+	tests := []struct {
+		name     string
+		physnets []PhysnetSection
+		expected []string
+	}{
+		{
+			name: "valid",
+			physnets: []PhysnetSection{
+				{Name: "datacentre", Bridge: "br-ex", VLANRanges: []string{"1:100", "200:300"}},
+				{Name: "tenant", Bridge: "br-tenant"},
+			},
+		},
+		{
+			name: "duplicate name",
+			physnets: []PhysnetSection{
+				{Name: "datacentre", Bridge: "br-ex"},
+				{Name: "datacentre", Bridge: "br-tenant"},
+			},
+			expected: []string{"FieldValueDuplicate physnets[1].name"},
+		},
+		{
+			name: "bridge shared by two physnets",
+			physnets: []PhysnetSection{
+				{Name: "datacentre", Bridge: "br-ex"},
+				{Name: "tenant", Bridge: "br-ex"},
+			},
+			expected: []string{"FieldValueInvalid physnets[1].bridge"},
+		},
+		{
+			name: "invalid VLAN ranges",
+			physnets: []PhysnetSection{
+				{Name: "datacentre", Bridge: "br-ex", VLANRanges: []string{"100:1", "0:10", "1:4095", "10-20"}},
+			},
+			expected: []string{
+				"FieldValueInvalid physnets[0].vlanRanges[0]",
+				"FieldValueInvalid physnets[0].vlanRanges[1]",
+				"FieldValueInvalid physnets[0].vlanRanges[2]",
+				"FieldValueInvalid physnets[0].vlanRanges[3]",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expectErrors(t, validatePhysnets(test.physnets, field.NewPath("physnets")), test.expected)
+		})
+	}
+}
+
+func TestValidateStorage(t *testing.T) {
+	tests := []struct {
+		name     string
+		storage  StorageSection
+		expected []string
+	}{
+		{
+			name:    "iSCSI portals",
+			storage: StorageSection{ISCSI: true, Targets: []string{"192.168.24.10:3260", "[fd00::10]:3260", "san.example.com:3260"}},
+		},
+		{
+			name:    "FC WWPNs",
+			storage: StorageSection{FibreChannel: true, Targets: []string{"50060e801049cfd1", "50:06:0e:80:10:49:cf:d1"}},
+		},
+		{
+			name:     "iSCSI portal without iscsi",
+			storage:  StorageSection{FibreChannel: true, Targets: []string{"192.168.24.10:3260"}},
+			expected: []string{"FieldValueInvalid storage.targets[0]"},
+		},
+		{
+			name:     "WWPN without fibreChannel",
+			storage:  StorageSection{ISCSI: true, Targets: []string{"50060e801049cfd1"}},
+			expected: []string{"FieldValueInvalid storage.targets[0]"},
+		},
+		{
+			name:    "malformed targets",
+			storage: StorageSection{ISCSI: true, FibreChannel: true, Targets: []string{"192.168.24.10", "50060e801049cfd", "50:06:0e:80:10:49:cf"}},
+			expected: []string{
+				"FieldValueInvalid storage.targets[0]",
+				"FieldValueInvalid storage.targets[1]",
+				"FieldValueInvalid storage.targets[2]",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expectErrors(t, validateStorage(&test.storage, field.NewPath("storage")), test.expected)
+		})
+	}
+}
+
+func TestValidateKernel(t *testing.T) {
+	tests := []struct {
+		name     string
+		kernel   KernelSection
+		expected []string
+	}{
+		{
+			name: "valid",
+			kernel: KernelSection{
+				Sysctls: map[string]string{"net.ipv4.ip_forward": "1", "net.ipv4.conf.eth0.rp_filter": "0"},
+				Modules: []KernelModuleSection{
+					{Name: "vfio_pci", Options: "ids=8086:154c"},
+					{Name: "floppy", Blacklist: true},
+				},
+			},
+		},
+		{
+			name:     "invalid sysctl names",
+			kernel:   KernelSection{Sysctls: map[string]string{"ip_forward": "1", "net.ipv4 .ip_forward": "1", "Net.ipv4.ip_forward": "1"}},
+			expected: []string{"FieldValueInvalid kernel.sysctls[Net.ipv4.ip_forward]", "FieldValueInvalid kernel.sysctls[ip_forward]", "FieldValueInvalid kernel.sysctls[net.ipv4 .ip_forward]"},
+		},
+		{
+			name:     "empty sysctl value",
+			kernel:   KernelSection{Sysctls: map[string]string{"vm.swappiness": ""}},
+			expected: []string{"FieldValueRequired kernel.sysctls[vm.swappiness]"},
+		},
+		{
+			name: "protected modules blacklisted",
+			kernel: KernelSection{Modules: []KernelModuleSection{
+				{Name: "openvswitch", Blacklist: true},
+				{Name: "br_netfilter", Blacklist: true},
+			}},
+			expected: []string{"FieldValueForbidden kernel.modules[0].blacklist", "FieldValueForbidden kernel.modules[1].blacklist"},
+		},
+		{
+			name:   "protected module loaded with options",
+			kernel: KernelSection{Modules: []KernelModuleSection{{Name: "openvswitch", Options: "debug=1"}}},
+		},
+		{
+			name: "duplicate module",
+			kernel: KernelSection{Modules: []KernelModuleSection{
+				{Name: "vfio_pci"},
+				{Name: "vfio_pci", Options: "ids=8086:154c"},
+			}},
+			expected: []string{"FieldValueDuplicate kernel.modules[1].name"},
+		},
+		{
+			name:     "options on blacklisted module",
+			kernel:   KernelSection{Modules: []KernelModuleSection{{Name: "floppy", Blacklist: true, Options: "allowed_drive_mask=0"}}},
+			expected: []string{"FieldValueInvalid kernel.modules[0].options"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expectErrors(t, validateKernel(&test.kernel, field.NewPath("kernel")), test.expected)
+		})
+	}
+}
+
+func TestValidateNFSMounts(t *testing.T) {
+	tests := []struct {
+		name     string
+		mounts   []NFSMountSection
+		expected []string
+	}{
+		{
+			name: "valid",
+			mounts: []NFSMountSection{
+				{Server: "nfs.example.com", Export: "/exports/nova", MountPoint: "/var/lib/nova/instances"},
+				{Server: "192.168.24.5", Export: "/exports/glance", MountPoint: "/var/lib/glance/image-cache", Options: "vers=4.2"},
+			},
+		},
+		{
+			name:     "missing server",
+			mounts:   []NFSMountSection{{Export: "/exports/nova", MountPoint: "/var/lib/nova/instances"}},
+			expected: []string{"FieldValueRequired nfsMounts[0].server"},
+		},
+		{
+			name:   "relative paths",
+			mounts: []NFSMountSection{{Server: "nfs.example.com", Export: "exports/nova", MountPoint: "var/lib/nova/instances"}},
+			expected: []string{
+				"FieldValueInvalid nfsMounts[0].export",
+				"FieldValueInvalid nfsMounts[0].mountPoint",
+			},
+		},
+		{
+			name: "duplicate mount point after cleaning",
+			mounts: []NFSMountSection{
+				{Server: "nfs.example.com", Export: "/exports/nova", MountPoint: "/var/lib/nova/instances"},
+				{Server: "nfs.example.com", Export: "/exports/other", MountPoint: "/var/lib/nova/instances/"},
+			},
+			expected: []string{"FieldValueDuplicate nfsMounts[1].mountPoint"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expectErrors(t, validateNFSMounts(test.mounts, field.NewPath("nfsMounts")), test.expected)
+		})
+	}
+}
+
+func TestValidateContainerImages(t *testing.T) {
+	tests := []struct {
+		name     string
+		images   map[string]string
+		expected []string
+	}{
+		{
+			name: "valid",
+			images: map[string]string{
+				"edpm_nova_compute_image":   "quay.io/podified/nova-compute:canary",
+				"edpm_ovn_controller_image": "quay.io/podified/ovn-controller:canary",
+			},
+		},
+		{
+			name: "invalid variable names",
+			images: map[string]string{
+				"nova_compute_image":      "nova:canary",
+				"edpm_nova_compute":       "nova:canary",
+				"edpm_Nova_compute_image": "nova:canary",
+				"edpm__image":             "nova:canary",
+			},
+			expected: []string{
+				"FieldValueInvalid containerImages[edpm_Nova_compute_image]",
+				"FieldValueInvalid containerImages[edpm__image]",
+				"FieldValueInvalid containerImages[edpm_nova_compute]",
+				"FieldValueInvalid containerImages[nova_compute_image]",
+			},
+		},
+		{
+			name:     "empty image",
+			images:   map[string]string{"edpm_nova_compute_image": ""},
+			expected: []string{"FieldValueRequired containerImages[edpm_nova_compute_image]"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expectErrors(t, validateContainerImages(test.images, field.NewPath("containerImages")), test.expected)
+		})
+	}
+}
@@ -30,8 +30,66 @@ type OpenStackDataPlaneRoleSpec struct {
 	DataPlaneNodes []DataPlaneNodeSection `json:"dataPlaneNodes,omitempty"`
 
 	// +kubebuilder:validation:Optional
-	// NodeTemplate - node attributes specific to this roles
+	// NodeTemplate - node attributes specific to this roles, layered under
+	// the settings of each node. Sections such as realtime or physnets are
+	// used as a whole when the node does not set them
 	NodeTemplate NodeSection `json:"nodeTemplate,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// GlobalVars - Names of OpenStackDataPlaneGlobalVars providing ansible
+	// variables to the nodes of this role. When a variable is set in more than
+	// one of them the last one in the list wins, and variables generated for
+	// the node itself always take precedence.
+	GlobalVars []string `json:"globalVars,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// ManageEtcHosts - Whether to render the host names and management IPs of
+	// all nodes of the role into an /etc/hosts fragment distributed to the
+	// nodes, for environments that can not rely on DNS during bootstrap
+	ManageEtcHosts bool `json:"manageEtcHosts,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// ReadinessPolicy - Which conditions decide whether the role is Ready
+	ReadinessPolicy ReadinessPolicySection `json:"readinessPolicy,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// AutoRepairOrphans - Whether to delete orphaned resources found by the
+	// audit automatically. A single repair can be requested by annotating
+	// the role with dataplane.openstack.org/repair-orphans
+	AutoRepairOrphans bool `json:"autoRepairOrphans,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// ResourceMetadata - Labels and annotations added to every resource
+	// generated for the role and its nodes, so that they can be selected
+	// uniformly, e.g. for cost allocation, backups or network policies
+	ResourceMetadata ResourceMetadataSection `json:"resourceMetadata,omitempty"`
+}
+
+type ResourceMetadataSection struct {
+	// +kubebuilder:validation:Optional
+	// Labels - Labels added to the generated resources. Labels set by the
+	// operator itself take precedence
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Annotations - Annotations added to the generated resources
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type ReadinessPolicySection struct {
+	// +kubebuilder:validation:Optional
+	// RequiredConditions - Condition types that must not be False, on the
+	// role or on any of its nodes, for the role to be Ready. Conditions that
+	// are not set are not taken into account. Defaults to AnsibleCredentials,
+	// GlobalVars, PhysnetsConsistent and KubernetesNodeLinked. InventoryReady
+	// may be required too; Ready and unknown condition types are rejected
+	RequiredConditions []string `json:"requiredConditions,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// IncludeNodesInMaintenance - Whether nodes in maintenance are taken into
+	// account for readiness and for the AnsibleCredentials and
+	// PhysnetsConsistent conditions of the role. By default they are left out
+	IncludeNodesInMaintenance bool `json:"includeNodesInMaintenance,omitempty"`
 }
 
 type DataPlaneNodeSection struct {
@@ -47,10 +105,24 @@ type DataPlaneNodeSection struct {
 
 // OpenStackDataPlaneRoleStatus defines the observed state of OpenStackDataPlaneRole
 type OpenStackDataPlaneRoleStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// +operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors={"urn:alm:descriptor:io.kubernetes.conditions"}
+	// Conditions
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// OrphanedResources - Generated resources that reference the role but do
+	// not belong to any of its current nodes, as Kind/name
+	OrphanedResources []string `json:"orphanedResources,omitempty"`
+
+	// ImageOverrides - Container images of nodes that diverge from the node
+	// template of the role layered over the default node template, as
+	// "node: variable=image"
+	ImageOverrides []string `json:"imageOverrides,omitempty"`
 }
 
+// RepairOrphansAnnotation requests a one-off deletion of the orphaned
+// resources of a role. It is removed once the repair is done.
+const RepairOrphansAnnotation = "dataplane.openstack.org/repair-orphans"
+
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 
@@ -0,0 +1,126 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// reservedInventoryGroups are the group names ansible gives a meaning to,
+// which roles can not be named after
+var reservedInventoryGroups = map[string]bool{
+	"all":       true,
+	"ungrouped": true,
+	"_meta":     true,
+}
+
+// log is for logging in this package.
+var openstackdataplanerolelog = logf.Log.WithName("openstackdataplanerole-resource")
+
+// SetupWebhookWithManager sets up the webhook with the Manager
+func (r *OpenStackDataPlaneRole) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-core-openstack-org-v1beta1-openstackdataplanerole,mutating=false,failurePolicy=fail,sideEffects=None,groups=core.openstack.org,resources=openstackdataplaneroles,verbs=create;update,versions=v1beta1,name=vopenstackdataplanerole.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &OpenStackDataPlaneRole{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *OpenStackDataPlaneRole) ValidateCreate() error {
+	openstackdataplanerolelog.Info("validate create", "name", r.Name)
+
+	// The name of the role is the group of its nodes in the dynamic
+	// inventory, it must not clash with the groups ansible reserves
+	if reservedInventoryGroups[r.Name] {
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: GroupVersion.Group, Kind: "OpenStackDataPlaneRole"},
+			r.Name, field.ErrorList{field.Invalid(field.NewPath("metadata", "name"), r.Name,
+				"name is reserved by ansible inventories")})
+	}
+
+	return r.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *OpenStackDataPlaneRole) ValidateUpdate(old runtime.Object) error {
+	openstackdataplanerolelog.Info("validate update", "name", r.Name)
+
+	return r.validate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *OpenStackDataPlaneRole) ValidateDelete() error {
+	openstackdataplanerolelog.Info("validate delete", "name", r.Name)
+
+	return nil
+}
+
+// validate checks the node template of the role, whose settings are layered
+// under those of its nodes, the nodes defined inline and its readiness policy
+func (r *OpenStackDataPlaneRole) validate() error {
+	allErrs := validateNode(&r.Spec.NodeTemplate, field.NewPath("spec", "nodeTemplate"))
+	for idx := range r.Spec.DataPlaneNodes {
+		allErrs = append(allErrs, validateNode(&r.Spec.DataPlaneNodes[idx].Node,
+			field.NewPath("spec", "dataPlaneNodes").Index(idx).Child("node"))...)
+	}
+	allErrs = append(allErrs, validateReadinessPolicy(
+		&r.Spec.ReadinessPolicy, field.NewPath("spec", "readinessPolicy"))...)
+	if len(allErrs) != 0 {
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: GroupVersion.Group, Kind: "OpenStackDataPlaneRole"},
+			r.Name, allErrs)
+	}
+
+	return nil
+}
+
+// validateReadinessPolicy checks that the policy only requires known
+// condition types, as an unknown one is never set and would silently be
+// ignored
+func validateReadinessPolicy(policy *ReadinessPolicySection, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	known := make(map[string]bool, len(ReadinessConditions))
+	for _, conditionType := range ReadinessConditions {
+		known[conditionType] = true
+	}
+	seen := make(map[string]bool, len(policy.RequiredConditions))
+	for i, conditionType := range policy.RequiredConditions {
+		conditionPath := path.Child("requiredConditions").Index(i)
+		switch {
+		case conditionType == ReadyCondition:
+			allErrs = append(allErrs, field.Invalid(conditionPath, conditionType,
+				"Ready is the result of the readiness policy and can not be required by it"))
+		case !known[conditionType]:
+			allErrs = append(allErrs, field.NotSupported(conditionPath, conditionType, ReadinessConditions))
+		case seen[conditionType]:
+			allErrs = append(allErrs, field.Duplicate(conditionPath, conditionType))
+		}
+		seen[conditionType] = true
+	}
+
+	return allErrs
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestValidateReadinessPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []string
+		expected   []string
+	}{
+		{
+			name: "default",
+		},
+		{
+			name:       "known conditions",
+			conditions: []string{AnsibleCredentialsCondition, InventoryReadyCondition},
+		},
+		{
+			name:       "typo",
+			conditions: []string{"AnsibleCredential"},
+			expected:   []string{"FieldValueNotSupported readinessPolicy.requiredConditions[0]"},
+		},
+		{
+			name:       "ready",
+			conditions: []string{GlobalVarsCondition, ReadyCondition},
+			expected:   []string{"FieldValueInvalid readinessPolicy.requiredConditions[1]"},
+		},
+		{
+			name:       "duplicate",
+			conditions: []string{GlobalVarsCondition, GlobalVarsCondition},
+			expected:   []string{"FieldValueDuplicate readinessPolicy.requiredConditions[1]"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			policy := &ReadinessPolicySection{RequiredConditions: test.conditions}
+			expectErrors(t, validateReadinessPolicy(policy, field.NewPath("readinessPolicy")), test.expected)
+		})
+	}
+}
+
+func TestValidateRoleNodes(t *testing.T) {
+	role := &OpenStackDataPlaneRole{
+		Spec: OpenStackDataPlaneRoleSpec{
+			NodeTemplate: NodeSection{
+				Physnets: []PhysnetSection{{Name: "datacentre", Bridge: "br-ex", VLANRanges: []string{"1:100"}}},
+			},
+			DataPlaneNodes: []DataPlaneNodeSection{
+				{NodeFrom: "compute-0"},
+				{Node: NodeSection{
+					HostName: "compute-1",
+					Physnets: []PhysnetSection{{Name: "datacentre", Bridge: "br-ex", VLANRanges: []string{"100:1"}}},
+				}},
+			},
+		},
+	}
+
+	err := role.ValidateCreate()
+	if err == nil {
+		t.Fatal("expected invalid physnets of an inline node to be rejected")
+	}
+	if !strings.Contains(err.Error(), "spec.dataPlaneNodes[1].node.physnets[0].vlanRanges[0]") {
+		t.Errorf("expected the error to point to the inline node, got %v", err)
+	}
+
+	role.Spec.DataPlaneNodes[1].Node.Physnets[0].VLANRanges = []string{"1:100"}
+	if err := role.ValidateCreate(); err != nil {
+		t.Errorf("expected a valid role to be accepted, got %v", err)
+	}
+}
+
+func TestValidateRoleName(t *testing.T) {
+	for _, name := range []string{"all", "ungrouped", "_meta"} {
+		role := &OpenStackDataPlaneRole{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if err := role.ValidateCreate(); err == nil {
+			t.Errorf("expected role name %q to be rejected", name)
+		}
+	}
+
+	role := &OpenStackDataPlaneRole{ObjectMeta: metav1.ObjectMeta{Name: "compute"}}
+	if err := role.ValidateCreate(); err != nil {
+		t.Errorf("expected role name compute to be accepted, got %v", err)
+	}
+}
@@ -22,6 +22,8 @@ limitations under the License.
 package v1beta1
 
 import (
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -41,6 +43,63 @@ func (in *DataPlaneNodeSection) DeepCopy() *DataPlaneNodeSection {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KernelModuleSection) DeepCopyInto(out *KernelModuleSection) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KernelModuleSection.
+func (in *KernelModuleSection) DeepCopy() *KernelModuleSection {
+	if in == nil {
+		return nil
+	}
+	out := new(KernelModuleSection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KernelSection) DeepCopyInto(out *KernelSection) {
+	*out = *in
+	if in.Sysctls != nil {
+		in, out := &in.Sysctls, &out.Sysctls
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Modules != nil {
+		in, out := &in.Modules, &out.Modules
+		*out = make([]KernelModuleSection, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KernelSection.
+func (in *KernelSection) DeepCopy() *KernelSection {
+	if in == nil {
+		return nil
+	}
+	out := new(KernelSection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NFSMountSection) DeepCopyInto(out *NFSMountSection) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NFSMountSection.
+func (in *NFSMountSection) DeepCopy() *NFSMountSection {
+	if in == nil {
+		return nil
+	}
+	out := new(NFSMountSection)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkConfigSection) DeepCopyInto(out *NetworkConfigSection) {
 	*out = *in
@@ -80,6 +139,40 @@ func (in *NodeSection) DeepCopyInto(out *NodeSection) {
 		*out = make([]NetworksSection, len(*in))
 		copy(*out, *in)
 	}
+	if in.Realtime != nil {
+		in, out := &in.Realtime, &out.Realtime
+		*out = new(RealtimeSection)
+		**out = **in
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(StorageSection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NFSMounts != nil {
+		in, out := &in.NFSMounts, &out.NFSMounts
+		*out = make([]NFSMountSection, len(*in))
+		copy(*out, *in)
+	}
+	if in.Kernel != nil {
+		in, out := &in.Kernel, &out.Kernel
+		*out = new(KernelSection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Physnets != nil {
+		in, out := &in.Physnets, &out.Physnets
+		*out = make([]PhysnetSection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ContainerImages != nil {
+		in, out := &in.ContainerImages, &out.ContainerImages
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeSection.
@@ -119,6 +212,160 @@ func (in *OpenStackDataPlane) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpenStackDataPlaneDefaultNodeTemplate) DeepCopyInto(out *OpenStackDataPlaneDefaultNodeTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenStackDataPlaneDefaultNodeTemplate.
+func (in *OpenStackDataPlaneDefaultNodeTemplate) DeepCopy() *OpenStackDataPlaneDefaultNodeTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(OpenStackDataPlaneDefaultNodeTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OpenStackDataPlaneDefaultNodeTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpenStackDataPlaneDefaultNodeTemplateList) DeepCopyInto(out *OpenStackDataPlaneDefaultNodeTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OpenStackDataPlaneDefaultNodeTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenStackDataPlaneDefaultNodeTemplateList.
+func (in *OpenStackDataPlaneDefaultNodeTemplateList) DeepCopy() *OpenStackDataPlaneDefaultNodeTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(OpenStackDataPlaneDefaultNodeTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OpenStackDataPlaneDefaultNodeTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpenStackDataPlaneDefaultNodeTemplateSpec) DeepCopyInto(out *OpenStackDataPlaneDefaultNodeTemplateSpec) {
+	*out = *in
+	in.NodeTemplate.DeepCopyInto(&out.NodeTemplate)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenStackDataPlaneDefaultNodeTemplateSpec.
+func (in *OpenStackDataPlaneDefaultNodeTemplateSpec) DeepCopy() *OpenStackDataPlaneDefaultNodeTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OpenStackDataPlaneDefaultNodeTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpenStackDataPlaneGlobalVars) DeepCopyInto(out *OpenStackDataPlaneGlobalVars) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenStackDataPlaneGlobalVars.
+func (in *OpenStackDataPlaneGlobalVars) DeepCopy() *OpenStackDataPlaneGlobalVars {
+	if in == nil {
+		return nil
+	}
+	out := new(OpenStackDataPlaneGlobalVars)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OpenStackDataPlaneGlobalVars) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpenStackDataPlaneGlobalVarsList) DeepCopyInto(out *OpenStackDataPlaneGlobalVarsList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OpenStackDataPlaneGlobalVars, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenStackDataPlaneGlobalVarsList.
+func (in *OpenStackDataPlaneGlobalVarsList) DeepCopy() *OpenStackDataPlaneGlobalVarsList {
+	if in == nil {
+		return nil
+	}
+	out := new(OpenStackDataPlaneGlobalVarsList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OpenStackDataPlaneGlobalVarsList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpenStackDataPlaneGlobalVarsSpec) DeepCopyInto(out *OpenStackDataPlaneGlobalVarsSpec) {
+	*out = *in
+	if in.Vars != nil {
+		in, out := &in.Vars, &out.Vars
+		*out = make(map[string]v1.JSON, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenStackDataPlaneGlobalVarsSpec.
+func (in *OpenStackDataPlaneGlobalVarsSpec) DeepCopy() *OpenStackDataPlaneGlobalVarsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OpenStackDataPlaneGlobalVarsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OpenStackDataPlaneList) DeepCopyInto(out *OpenStackDataPlaneList) {
 	*out = *in
@@ -157,7 +404,7 @@ func (in *OpenStackDataPlaneNode) DeepCopyInto(out *OpenStackDataPlaneNode) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenStackDataPlaneNode.
@@ -229,6 +476,18 @@ func (in *OpenStackDataPlaneNodeSpec) DeepCopy() *OpenStackDataPlaneNodeSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OpenStackDataPlaneNodeStatus) DeepCopyInto(out *OpenStackDataPlaneNodeStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AppliedDefaults != nil {
+		in, out := &in.AppliedDefaults, &out.AppliedDefaults
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenStackDataPlaneNodeStatus.
@@ -247,7 +506,7 @@ func (in *OpenStackDataPlaneRole) DeepCopyInto(out *OpenStackDataPlaneRole) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenStackDataPlaneRole.
@@ -311,6 +570,13 @@ func (in *OpenStackDataPlaneRoleSpec) DeepCopyInto(out *OpenStackDataPlaneRoleSp
 		}
 	}
 	in.NodeTemplate.DeepCopyInto(&out.NodeTemplate)
+	if in.GlobalVars != nil {
+		in, out := &in.GlobalVars, &out.GlobalVars
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.ReadinessPolicy.DeepCopyInto(&out.ReadinessPolicy)
+	in.ResourceMetadata.DeepCopyInto(&out.ResourceMetadata)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenStackDataPlaneRoleSpec.
@@ -326,6 +592,23 @@ func (in *OpenStackDataPlaneRoleSpec) DeepCopy() *OpenStackDataPlaneRoleSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OpenStackDataPlaneRoleStatus) DeepCopyInto(out *OpenStackDataPlaneRoleStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.OrphanedResources != nil {
+		in, out := &in.OrphanedResources, &out.OrphanedResources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ImageOverrides != nil {
+		in, out := &in.ImageOverrides, &out.ImageOverrides
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenStackDataPlaneRoleStatus.
@@ -374,3 +657,107 @@ func (in *OpenStackDataPlaneStatus) DeepCopy() *OpenStackDataPlaneStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PhysnetSection) DeepCopyInto(out *PhysnetSection) {
+	*out = *in
+	if in.VLANRanges != nil {
+		in, out := &in.VLANRanges, &out.VLANRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PhysnetSection.
+func (in *PhysnetSection) DeepCopy() *PhysnetSection {
+	if in == nil {
+		return nil
+	}
+	out := new(PhysnetSection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReadinessPolicySection) DeepCopyInto(out *ReadinessPolicySection) {
+	*out = *in
+	if in.RequiredConditions != nil {
+		in, out := &in.RequiredConditions, &out.RequiredConditions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadinessPolicySection.
+func (in *ReadinessPolicySection) DeepCopy() *ReadinessPolicySection {
+	if in == nil {
+		return nil
+	}
+	out := new(ReadinessPolicySection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RealtimeSection) DeepCopyInto(out *RealtimeSection) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RealtimeSection.
+func (in *RealtimeSection) DeepCopy() *RealtimeSection {
+	if in == nil {
+		return nil
+	}
+	out := new(RealtimeSection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceMetadataSection) DeepCopyInto(out *ResourceMetadataSection) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceMetadataSection.
+func (in *ResourceMetadataSection) DeepCopy() *ResourceMetadataSection {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceMetadataSection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageSection) DeepCopyInto(out *StorageSection) {
+	*out = *in
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageSection.
+func (in *StorageSection) DeepCopy() *StorageSection {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageSection)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// dataplane is a command line companion of the operator. It currently
+// validates node definitions before they are applied:
+//
+//	dataplane nodes validate -f nodes.yaml
+//
+// The file holds OpenStackDataPlaneNode objects, as a multi-document YAML or
+// JSON stream or as an OpenStackDataPlaneNodeList. Each node goes through the
+// same checks as the admission webhook, and host names and fixed IPs must be
+// unique across the file. The report is printed as JSON and the command
+// exits with 1 when a node is invalid.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	corev1beta1 "github.com/openstack-k8s-operators/dataplane-operator/api/v1beta1"
+)
+
+const usage = `Usage: dataplane nodes validate -f FILE
+
+Validate the OpenStackDataPlaneNodes in FILE ("-" for stdin) and print a
+JSON report.
+`
+
+// nodeReport is the validation outcome of a single node
+type nodeReport struct {
+	Name   string   `json:"name"`
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// report is the validation outcome of a node list
+type report struct {
+	Valid bool         `json:"valid"`
+	Nodes []nodeReport `json:"nodes"`
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command with args, excluding the program name, and
+// returns its exit code
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(args) < 2 || args[0] != "nodes" || args[1] != "validate" {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	flags := flag.NewFlagSet("dataplane nodes validate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { fmt.Fprint(stderr, usage) }
+	var file string
+	flags.StringVar(&file, "f", "", "File holding the node definitions, - for stdin")
+	if err := flags.Parse(args[2:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if file == "" {
+		flags.Usage()
+		return 2
+	}
+
+	in := stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		defer f.Close()
+		in = f
+	}
+
+	nodes, err := readNodes(in)
+	if err != nil {
+		fmt.Fprintf(stderr, "unable to read %s: %v\n", file, err)
+		return 2
+	}
+
+	result := validateNodes(nodes)
+	encoder := json.NewEncoder(stdout)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	if !result.Valid {
+		return 1
+	}
+	return 0
+}
+
+// readNodes decodes all OpenStackDataPlaneNodes of a YAML or JSON stream
+func readNodes(in io.Reader) ([]corev1beta1.OpenStackDataPlaneNode, error) {
+	var nodes []corev1beta1.OpenStackDataPlaneNode
+
+	decoder := k8syaml.NewYAMLOrJSONDecoder(in, 4096)
+	for {
+		var doc json.RawMessage
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return nodes, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(doc) == 0 || string(doc) == "null" {
+			continue
+		}
+
+		var typeMeta struct {
+			Kind string `json:"kind"`
+		}
+		if err := json.Unmarshal(doc, &typeMeta); err != nil {
+			return nil, err
+		}
+		switch typeMeta.Kind {
+		case "OpenStackDataPlaneNode":
+			node := corev1beta1.OpenStackDataPlaneNode{}
+			if err := json.Unmarshal(doc, &node); err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, node)
+		case "OpenStackDataPlaneNodeList":
+			list := corev1beta1.OpenStackDataPlaneNodeList{}
+			if err := json.Unmarshal(doc, &list); err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, list.Items...)
+		default:
+			return nil, fmt.Errorf("unexpected kind %q, expected OpenStackDataPlaneNode or OpenStackDataPlaneNodeList", typeMeta.Kind)
+		}
+	}
+}
+
+// validateNodes runs the admission checks on every node and checks that host
+// names and fixed IPs are not used by more than one node
+func validateNodes(nodes []corev1beta1.OpenStackDataPlaneNode) report {
+	result := report{Valid: true, Nodes: []nodeReport{}}
+
+	hostNames := make(map[string]string, len(nodes))
+	fixedIPs := make(map[string]string, len(nodes))
+	for _, node := range nodes {
+		nodeResult := nodeReport{Name: node.Name}
+
+		if err := node.ValidateCreate(); err != nil {
+			nodeResult.Errors = append(nodeResult.Errors, admissionErrors(err)...)
+		}
+
+		nodePath := field.NewPath("spec", "node")
+		if hostName := node.Spec.Node.HostName; hostName != "" {
+			if other, found := hostNames[hostName]; found {
+				nodeResult.Errors = append(nodeResult.Errors, field.Invalid(nodePath.Child("hostName"), hostName,
+					fmt.Sprintf("host name is already used by node %s", other)).Error())
+			} else {
+				hostNames[hostName] = node.Name
+			}
+		}
+		for idx, network := range node.Spec.Node.Networks {
+			if network.FixedIP == "" {
+				continue
+			}
+			if other, found := fixedIPs[network.FixedIP]; found {
+				nodeResult.Errors = append(nodeResult.Errors, field.Invalid(nodePath.Child("networks").Index(idx).Child("fixedIP"),
+					network.FixedIP, fmt.Sprintf("IP is already used by node %s", other)).Error())
+			} else {
+				fixedIPs[network.FixedIP] = node.Name
+			}
+		}
+
+		nodeResult.Valid = len(nodeResult.Errors) == 0
+		result.Valid = result.Valid && nodeResult.Valid
+		result.Nodes = append(result.Nodes, nodeResult)
+	}
+
+	return result
+}
+
+// admissionErrors lists the individual field errors of a webhook rejection
+func admissionErrors(err error) []string {
+	var statusErr *apierrors.StatusError
+	if !errors.As(err, &statusErr) || statusErr.ErrStatus.Details == nil || len(statusErr.ErrStatus.Details.Causes) == 0 {
+		return []string{err.Error()}
+	}
+
+	var errs []string
+	for _, cause := range statusErr.ErrStatus.Details.Causes {
+		errs = append(errs, strings.TrimPrefix(fmt.Sprintf("%s: %s", cause.Field, cause.Message), ": "))
+	}
+	return errs
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const nodesYAML = `
+apiVersion: core.openstack.org/v1beta1
+kind: OpenStackDataPlaneNode
+metadata:
+  name: compute-0
+spec:
+  node:
+    hostName: compute-0
+    networks:
+    - template: ctlplane
+      fixedIP: 192.168.122.100
+---
+apiVersion: core.openstack.org/v1beta1
+kind: OpenStackDataPlaneNodeList
+items:
+- apiVersion: core.openstack.org/v1beta1
+  kind: OpenStackDataPlaneNode
+  metadata:
+    name: compute-1
+  spec:
+    node:
+      hostName: compute-0
+      networks:
+      - template: ctlplane
+        fixedIP: 192.168.122.101
+- apiVersion: core.openstack.org/v1beta1
+  kind: OpenStackDataPlaneNode
+  metadata:
+    name: compute-2
+  spec:
+    node:
+      hostName: compute-2
+      networks:
+      - template: ctlplane
+        fixedIP: 192.168.122.100
+---
+`
+
+func TestReadNodes(t *testing.T) {
+	nodes, err := readNodes(strings.NewReader(nodesYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+	if want := []string{"compute-0", "compute-1", "compute-2"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("nodes = %v, want %v", names, want)
+	}
+
+	_, err = readNodes(strings.NewReader("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: other\n"))
+	if err == nil {
+		t.Error("expected a document of another kind to be rejected")
+	}
+}
+
+func TestValidateNodesDuplicates(t *testing.T) {
+	nodes, err := readNodes(strings.NewReader(nodesYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := validateNodes(nodes)
+	if result.Valid {
+		t.Fatal("expected duplicate host names and fixed IPs to be reported")
+	}
+	expected := []nodeReport{
+		{Name: "compute-0", Valid: true},
+		{Name: "compute-1", Errors: []string{
+			`spec.node.hostName: Invalid value: "compute-0": host name is already used by node compute-0`,
+		}},
+		{Name: "compute-2", Errors: []string{
+			`spec.node.networks[0].fixedIP: Invalid value: "192.168.122.100": IP is already used by node compute-0`,
+		}},
+	}
+	if !reflect.DeepEqual(result.Nodes, expected) {
+		t.Errorf("report = %+v, want %+v", result.Nodes, expected)
+	}
+}
+
+func TestRunReport(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"nodes", "validate", "-f", "-"}, strings.NewReader(nodesYAML), &stdout, &stderr)
+	if code != 1 {
+		t.Fatalf("exit code = %d, want 1 (stderr: %s)", code, stderr.String())
+	}
+
+	var document struct {
+		Valid *bool `json:"valid"`
+		Nodes []struct {
+			Name   *string  `json:"name"`
+			Valid  *bool    `json:"valid"`
+			Errors []string `json:"errors"`
+		} `json:"nodes"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &document); err != nil {
+		t.Fatalf("report is not JSON: %v\n%s", err, stdout.String())
+	}
+	if document.Valid == nil || *document.Valid {
+		t.Errorf("expected valid to be false in %s", stdout.String())
+	}
+	if len(document.Nodes) != 3 {
+		t.Fatalf("expected 3 nodes in %s", stdout.String())
+	}
+	for _, node := range document.Nodes {
+		if node.Name == nil || node.Valid == nil {
+			t.Errorf("expected name and valid on every node in %s", stdout.String())
+		}
+	}
+	if !strings.Contains(stdout.String(), `"errors": [`) || strings.Count(stdout.String(), `"errors"`) != 2 {
+		t.Errorf("expected errors only on invalid nodes in %s", stdout.String())
+	}
+
+	stdout.Reset()
+	code = run([]string{"nodes", "validate", "-f", "-"}, strings.NewReader(strings.SplitN(nodesYAML, "---", 2)[0]), &stdout, &stderr)
+	if code != 0 {
+		t.Errorf("exit code = %d for a valid file, want 0 (stderr: %s)", code, stderr.String())
+	}
+
+	if code := run([]string{"nodes"}, nil, &stdout, &stderr); code != 2 {
+		t.Errorf("exit code = %d for a usage error, want 2", code)
+	}
+	if code := run([]string{"nodes", "validate", "-f", "/nonexistent"}, nil, &stdout, &stderr); code != 2 {
+		t.Errorf("exit code = %d for a missing file, want 2", code)
+	}
+}
@@ -18,19 +18,26 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/go-logr/logr"
 	"gopkg.in/yaml.v2"
 	corev1 "k8s.io/api/core/v1"
 	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	corev1beta1 "github.com/openstack-k8s-operators/dataplane-operator/api/v1beta1"
 )
@@ -45,6 +52,12 @@ type OpenStackDataPlaneNodeReconciler struct {
 //+kubebuilder:rbac:groups=core.openstack.org,resources=openstackdataplanenodes,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core.openstack.org,resources=openstackdataplanenodes/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=core.openstack.org,resources=openstackdataplanenodes/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core.openstack.org,resources=openstackdataplaneroles,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core.openstack.org,resources=openstackdataplaneglobalvars,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core.openstack.org,resources=openstackdataplanedefaultnodetemplates,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -72,6 +85,55 @@ func (r *OpenStackDataPlaneNodeReconciler) Reconcile(ctx context.Context, req ct
 		return ctrl.Result{}, err
 	}
 
+	if !instance.DeletionTimestamp.IsZero() {
+		// Remove the metadata set on the linked Kubernetes Node
+		return ctrl.Result{}, r.unlinkKubernetesNodes(ctx, instance)
+	}
+
+	if instance.Spec.Node.Maintenance {
+		meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+			Type:    corev1beta1.NodeMaintenanceCondition,
+			Status:  metav1.ConditionTrue,
+			Reason:  corev1beta1.MaintenanceEnabledReason,
+			Message: "Node is in maintenance and is excluded from provisioning and configuration",
+		})
+		return ctrl.Result{}, r.Status().Update(ctx, instance)
+	}
+
+	if meta.IsStatusConditionTrue(instance.Status.Conditions, corev1beta1.NodeMaintenanceCondition) {
+		r.Log.Info(fmt.Sprintf("OpenStackDataPlaneNode %s left maintenance, its configuration may be stale", instance.Name))
+		meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+			Type:    corev1beta1.NodeMaintenanceCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  corev1beta1.MaintenanceClearedReason,
+			Message: "Node left maintenance; its configuration may be stale until it is redeployed",
+		})
+		err = r.Status().Update(ctx, instance)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	err = r.ValidateAnsibleCredentials(ctx, instance)
+	if err != nil {
+		r.Log.Error(err, fmt.Sprintf("Unable to validate ansible credentials for %s", instance.Name))
+		return ctrl.Result{}, err
+	}
+	if cond := meta.FindStatusCondition(instance.Status.Conditions, corev1beta1.AnsibleCredentialsCondition); cond != nil && cond.Status == metav1.ConditionFalse {
+		// Do not go any further with a node we could not log into
+		return ctrl.Result{}, nil
+	}
+
+	err = r.ValidateGlobalVars(ctx, instance)
+	if err != nil {
+		r.Log.Error(err, fmt.Sprintf("Unable to validate global vars for %s", instance.Name))
+		return ctrl.Result{}, err
+	}
+	if meta.IsStatusConditionFalse(instance.Status.Conditions, corev1beta1.GlobalVarsCondition) {
+		// The inventory would miss variables, wait for them to be created
+		return ctrl.Result{}, nil
+	}
+
 	if instance.Spec.Node.Managed {
 		err = r.Provision(ctx, instance)
 		if err != nil {
@@ -86,6 +148,17 @@ func (r *OpenStackDataPlaneNodeReconciler) Reconcile(ctx context.Context, req ct
 		return ctrl.Result{}, err
 	}
 
+	err = r.LinkKubernetesNode(ctx, instance)
+	if err != nil {
+		r.Log.Error(err, fmt.Sprintf("Unable to link OpenStackDataPlaneNode %s to a Kubernetes Node", instance.Name))
+		return ctrl.Result{}, err
+	}
+	if cond := meta.FindStatusCondition(instance.Status.Conditions, corev1beta1.KubernetesNodeLinkedCondition); cond != nil && cond.Reason == corev1beta1.KubernetesNodeUnschedulableReason {
+		// Network configuration is disruptive, wait for the node to be
+		// schedulable again
+		return ctrl.Result{}, nil
+	}
+
 	r.ConfigureNetwork(ctx, instance)
 
 	return ctrl.Result{}, nil
@@ -95,9 +168,615 @@ func (r *OpenStackDataPlaneNodeReconciler) Reconcile(ctx context.Context, req ct
 func (r *OpenStackDataPlaneNodeReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&corev1beta1.OpenStackDataPlaneNode{}).
+		Owns(&corev1.ConfigMap{}).
+		Owns(&corev1.Secret{}).
+		Watches(&source.Kind{Type: &corev1beta1.OpenStackDataPlaneRole{}},
+			handler.EnqueueRequestsFromMapFunc(r.roleToNodes)).
+		Watches(&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(r.secretToNodes)).
+		Watches(&source.Kind{Type: &corev1beta1.OpenStackDataPlaneGlobalVars{}},
+			handler.EnqueueRequestsFromMapFunc(r.globalVarsToNodes)).
+		Watches(&source.Kind{Type: &corev1beta1.OpenStackDataPlaneDefaultNodeTemplate{}},
+			handler.EnqueueRequestsFromMapFunc(r.defaultNodeTemplateToNodes)).
+		Watches(&source.Kind{Type: &corev1.Node{}},
+			handler.EnqueueRequestsFromMapFunc(r.kubernetesNodeToNodes)).
 		Complete(r)
 }
 
+// kubernetesNodeToNodes maps a Kubernetes Node to reconcile requests for the
+// OpenStackDataPlaneNode linked to it, using the labels set when linking, and
+// for the nodes of all namespaces that link Kubernetes Nodes and list one of
+// its addresses, so that workers joining later or changing their address get
+// linked
+func (r *OpenStackDataPlaneNodeReconciler) kubernetesNodeToNodes(obj client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+	name, hasName := obj.GetLabels()[corev1beta1.KubernetesNodeLabel]
+	namespace, hasNamespace := obj.GetAnnotations()[kubernetesNodeNamespaceAnnotation]
+	if hasName && hasNamespace {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKey{Namespace: namespace, Name: name},
+		})
+	}
+
+	kubeNode, ok := obj.(*corev1.Node)
+	if !ok {
+		return requests
+	}
+	addresses := map[string]bool{}
+	for _, address := range kubeNode.Status.Addresses {
+		addresses[address.Address] = true
+	}
+
+	nodeList := &corev1beta1.OpenStackDataPlaneNodeList{}
+	err := r.Client.List(context.Background(), nodeList)
+	if err != nil {
+		r.Log.Error(err, fmt.Sprintf("Unable to list nodes matching Kubernetes Node %s", obj.GetName()))
+		return requests
+	}
+	for _, node := range nodeList.Items {
+		if !node.Spec.Node.LinkKubernetesNode || (node.Name == name && node.Namespace == namespace) {
+			continue
+		}
+		if matchesAddresses(node.Spec.Node, addresses) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: client.ObjectKey{Namespace: node.Namespace, Name: node.Name},
+			})
+		}
+	}
+	return requests
+}
+
+// matchesAddresses reports whether the host name, ansible host or one of the
+// fixed IPs of node is among addresses. The fixed IPs of all networks are
+// considered, as the management network may be set by a template.
+func matchesAddresses(node corev1beta1.NodeSection, addresses map[string]bool) bool {
+	if addresses[node.HostName] || addresses[node.AnsibleHost] {
+		return true
+	}
+	for _, network := range node.Networks {
+		if addresses[network.FixedIP] {
+			return true
+		}
+	}
+	return false
+}
+
+// roleToNodes maps an OpenStackDataPlaneRole to reconcile requests for all
+// of its nodes
+func (r *OpenStackDataPlaneNodeReconciler) roleToNodes(obj client.Object) []reconcile.Request {
+	nodeList := &corev1beta1.OpenStackDataPlaneNodeList{}
+	err := r.Client.List(context.Background(), nodeList, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		r.Log.Error(err, fmt.Sprintf("Unable to list nodes of OpenStackDataPlaneRole %s", obj.GetName()))
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, node := range nodeList.Items {
+		if node.Spec.Role == obj.GetName() {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: client.ObjectKey{Namespace: node.Namespace, Name: node.Name},
+			})
+		}
+	}
+	return requests
+}
+
+// globalVarsToNodes maps an OpenStackDataPlaneGlobalVars to reconcile
+// requests for the nodes of every role referencing it
+func (r *OpenStackDataPlaneNodeReconciler) globalVarsToNodes(obj client.Object) []reconcile.Request {
+	roleList := &corev1beta1.OpenStackDataPlaneRoleList{}
+	err := r.Client.List(context.Background(), roleList, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		r.Log.Error(err, fmt.Sprintf("Unable to list roles referencing OpenStackDataPlaneGlobalVars %s", obj.GetName()))
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i, role := range roleList.Items {
+		for _, name := range role.Spec.GlobalVars {
+			if name == obj.GetName() {
+				requests = append(requests, r.roleToNodes(&roleList.Items[i])...)
+				break
+			}
+		}
+	}
+	return requests
+}
+
+// secretToNodes maps a Secret to reconcile requests of the nodes using it as
+// ansible password Secret, either directly or through the node template of
+// their role or the default node template, so that nodes waiting for their
+// credentials are reconciled once the Secret is created or fixed
+func (r *OpenStackDataPlaneNodeReconciler) secretToNodes(obj client.Object) []reconcile.Request {
+	ctx := context.Background()
+
+	defaults := &corev1beta1.OpenStackDataPlaneDefaultNodeTemplate{}
+	err := r.Client.Get(ctx, client.ObjectKey{Namespace: obj.GetNamespace(), Name: corev1beta1.DefaultNodeTemplateName}, defaults)
+	if err != nil && !k8s_errors.IsNotFound(err) {
+		r.Log.Error(err, fmt.Sprintf("Unable to get the default node template for Secret %s", obj.GetName()))
+		return nil
+	}
+	if err == nil && defaults.Spec.NodeTemplate.AnsiblePasswordSecret == obj.GetName() {
+		return r.defaultNodeTemplateToNodes(defaults)
+	}
+
+	roleList := &corev1beta1.OpenStackDataPlaneRoleList{}
+	err = r.Client.List(ctx, roleList, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		r.Log.Error(err, fmt.Sprintf("Unable to list roles using Secret %s", obj.GetName()))
+		return nil
+	}
+	roles := make(map[string]bool)
+	for _, role := range roleList.Items {
+		if role.Spec.NodeTemplate.AnsiblePasswordSecret == obj.GetName() {
+			roles[role.Name] = true
+		}
+	}
+
+	nodeList := &corev1beta1.OpenStackDataPlaneNodeList{}
+	err = r.Client.List(ctx, nodeList, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		r.Log.Error(err, fmt.Sprintf("Unable to list nodes using Secret %s", obj.GetName()))
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, node := range nodeList.Items {
+		if node.Spec.Node.AnsiblePasswordSecret == obj.GetName() || roles[node.Spec.Role] {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: client.ObjectKey{Namespace: node.Namespace, Name: node.Name},
+			})
+		}
+	}
+	return requests
+}
+
+// defaultNodeTemplateToNodes maps the default node template of a namespace
+// to reconcile requests of all nodes of the namespace
+func (r *OpenStackDataPlaneNodeReconciler) defaultNodeTemplateToNodes(obj client.Object) []reconcile.Request {
+	if obj.GetName() != corev1beta1.DefaultNodeTemplateName {
+		return nil
+	}
+
+	nodeList := &corev1beta1.OpenStackDataPlaneNodeList{}
+	err := r.Client.List(context.Background(), nodeList, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		r.Log.Error(err, fmt.Sprintf("Unable to list nodes using OpenStackDataPlaneDefaultNodeTemplate %s", obj.GetName()))
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(nodeList.Items))
+	for _, node := range nodeList.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKey{Namespace: node.Namespace, Name: node.Name},
+		})
+	}
+	return requests
+}
+
+// GetRole returns the OpenStackDataPlaneRole the node belongs to, or nil if
+// the node is not part of a role
+func (r *OpenStackDataPlaneNodeReconciler) GetRole(ctx context.Context, instance *corev1beta1.OpenStackDataPlaneNode) (*corev1beta1.OpenStackDataPlaneRole, error) {
+	if instance.Spec.Role == "" {
+		return nil, nil
+	}
+
+	role := &corev1beta1.OpenStackDataPlaneRole{}
+	err := r.Client.Get(ctx, client.ObjectKey{Namespace: instance.Namespace, Name: instance.Spec.Role}, role)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return role, nil
+}
+
+// GetGlobalVars merges the variables of all OpenStackDataPlaneGlobalVars
+// referenced by the role, later entries overriding earlier ones. It also
+// returns the names of the referenced objects that do not exist.
+func (r *OpenStackDataPlaneNodeReconciler) GetGlobalVars(ctx context.Context, role *corev1beta1.OpenStackDataPlaneRole) (map[string]interface{}, []string, error) {
+	vars := make(map[string]interface{})
+	if role == nil {
+		return vars, nil, nil
+	}
+
+	var missing []string
+	for _, name := range role.Spec.GlobalVars {
+		globalVars := &corev1beta1.OpenStackDataPlaneGlobalVars{}
+		err := r.Client.Get(ctx, client.ObjectKey{Namespace: role.Namespace, Name: name}, globalVars)
+		if k8s_errors.IsNotFound(err) {
+			missing = append(missing, name)
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to get OpenStackDataPlaneGlobalVars %s referenced by role %s: %w", name, role.Name, err)
+		}
+		for key, value := range globalVars.Spec.Vars {
+			var decoded interface{}
+			err = json.Unmarshal(value.Raw, &decoded)
+			if err != nil {
+				return nil, nil, fmt.Errorf("unable to decode var %s of OpenStackDataPlaneGlobalVars %s: %w", key, name, err)
+			}
+			vars[key] = decoded
+		}
+	}
+
+	return vars, missing, nil
+}
+
+// ValidateGlobalVars records in the GlobalVars condition whether all
+// OpenStackDataPlaneGlobalVars referenced by the role of the node exist. The
+// node is reconciled again when a missing one is created.
+func (r *OpenStackDataPlaneNodeReconciler) ValidateGlobalVars(ctx context.Context, instance *corev1beta1.OpenStackDataPlaneNode) error {
+	role, err := r.GetRole(ctx, instance)
+	if err != nil {
+		return err
+	}
+	if role == nil || len(role.Spec.GlobalVars) == 0 {
+		meta.RemoveStatusCondition(&instance.Status.Conditions, corev1beta1.GlobalVarsCondition)
+		return r.Status().Update(ctx, instance)
+	}
+
+	_, missing, err := r.GetGlobalVars(ctx, role)
+	if err != nil {
+		return err
+	}
+	condition := metav1.Condition{
+		Type:    corev1beta1.GlobalVarsCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  corev1beta1.GlobalVarsResolvedReason,
+		Message: fmt.Sprintf("Global vars %s of role %s exist", strings.Join(role.Spec.GlobalVars, ", "), role.Name),
+	}
+	if len(missing) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = corev1beta1.GlobalVarsNotFoundReason
+		condition.Message = fmt.Sprintf("OpenStackDataPlaneGlobalVars %s referenced by role %s do not exist",
+			strings.Join(missing, ", "), role.Name)
+	}
+	meta.SetStatusCondition(&instance.Status.Conditions, condition)
+
+	return r.Status().Update(ctx, instance)
+}
+
+// GetEffectiveNode returns the node settings layered over the node template
+// of its role and the default node template of the namespace, the node taking
+// precedence, and records the fields taken from the default node template in
+// status.appliedDefaults
+func (r *OpenStackDataPlaneNodeReconciler) GetEffectiveNode(ctx context.Context, instance *corev1beta1.OpenStackDataPlaneNode) (corev1beta1.NodeSection, error) {
+	role, err := r.GetRole(ctx, instance)
+	if err != nil {
+		return instance.Spec.Node, err
+	}
+	defaults, err := getDefaultNodeTemplate(ctx, r.Client, instance.Namespace)
+	if err != nil {
+		return instance.Spec.Node, err
+	}
+
+	node, applied := effectiveNode(instance.Spec.Node, role, defaults)
+	instance.Status.AppliedDefaults = applied
+
+	return node, nil
+}
+
+// getDefaultNodeTemplate returns the default node template of the namespace,
+// or nil if there is none
+func getDefaultNodeTemplate(ctx context.Context, c client.Client, namespace string) (*corev1beta1.OpenStackDataPlaneDefaultNodeTemplate, error) {
+	defaults := &corev1beta1.OpenStackDataPlaneDefaultNodeTemplate{}
+	err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: corev1beta1.DefaultNodeTemplateName}, defaults)
+	if k8s_errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return defaults, nil
+}
+
+// effectiveNode layers node over the node template of role and the default
+// node template, either of which may be nil, and returns the resulting
+// settings along with the fields taken from the default node template
+func effectiveNode(node corev1beta1.NodeSection, role *corev1beta1.OpenStackDataPlaneRole, defaults *corev1beta1.OpenStackDataPlaneDefaultNodeTemplate) (corev1beta1.NodeSection, []string) {
+	effective := *node.DeepCopy()
+	if role != nil {
+		layerNodeDefaults(&effective, role.Spec.NodeTemplate)
+	}
+	var applied []string
+	if defaults != nil {
+		applied = layerNodeDefaults(&effective, defaults.Spec.NodeTemplate)
+	}
+
+	return effective, applied
+}
+
+// layerNodeDefaults fills the fields of node that are not set from template
+// and returns the names of the fields it filled
+func layerNodeDefaults(node *corev1beta1.NodeSection, template corev1beta1.NodeSection) []string {
+	var applied []string
+	if node.AnsibleUser == "" && template.AnsibleUser != "" {
+		node.AnsibleUser = template.AnsibleUser
+		applied = append(applied, "ansibleUser")
+	}
+	if node.AnsiblePasswordSecret == "" && template.AnsiblePasswordSecret != "" {
+		node.AnsiblePasswordSecret = template.AnsiblePasswordSecret
+		applied = append(applied, "ansiblePasswordSecret")
+	}
+	if node.AnsiblePort == 0 && template.AnsiblePort != 0 {
+		node.AnsiblePort = template.AnsiblePort
+		applied = append(applied, "ansiblePort")
+	}
+	if node.ManagementNetwork == "" && template.ManagementNetwork != "" {
+		node.ManagementNetwork = template.ManagementNetwork
+		applied = append(applied, "managementNetwork")
+	}
+
+	// Sections are taken as a whole, so a node setting one replaces that of
+	// the template rather than being merged with it
+	if node.Realtime == nil && template.Realtime != nil {
+		node.Realtime = template.Realtime.DeepCopy()
+		applied = append(applied, "realtime")
+	}
+	if node.Storage == nil && template.Storage != nil {
+		node.Storage = template.Storage.DeepCopy()
+		applied = append(applied, "storage")
+	}
+	if len(node.NFSMounts) == 0 && len(template.NFSMounts) > 0 {
+		node.NFSMounts = append([]corev1beta1.NFSMountSection(nil), template.NFSMounts...)
+		applied = append(applied, "nfsMounts")
+	}
+	if node.Kernel == nil && template.Kernel != nil {
+		node.Kernel = template.Kernel.DeepCopy()
+		applied = append(applied, "kernel")
+	}
+	if len(node.Physnets) == 0 && len(template.Physnets) > 0 {
+		for _, physnet := range template.Physnets {
+			node.Physnets = append(node.Physnets, *physnet.DeepCopy())
+		}
+		applied = append(applied, "physnets")
+	}
+
+	names := make([]string, 0, len(template.ContainerImages))
+	for name := range template.ContainerImages {
+		if _, found := node.ContainerImages[name]; !found {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if node.ContainerImages == nil {
+			node.ContainerImages = make(map[string]string)
+		}
+		node.ContainerImages[name] = template.ContainerImages[name]
+		applied = append(applied, fmt.Sprintf("containerImages.%s", name))
+	}
+
+	return applied
+}
+
+// ValidateAnsibleCredentials runs the preflight checks on the credentials of
+// the ansible user and records the outcome in the AnsibleCredentials
+// condition. An error is only returned when the checks themselves could not
+// be run.
+func (r *OpenStackDataPlaneNodeReconciler) ValidateAnsibleCredentials(ctx context.Context, instance *corev1beta1.OpenStackDataPlaneNode) error {
+	node, err := r.GetEffectiveNode(ctx, instance)
+	if err != nil {
+		return err
+	}
+	if node.AnsiblePasswordSecret == "" {
+		meta.RemoveStatusCondition(&instance.Status.Conditions, corev1beta1.AnsibleCredentialsCondition)
+		return r.Status().Update(ctx, instance)
+	}
+
+	condition := metav1.Condition{
+		Type:    corev1beta1.AnsibleCredentialsCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  corev1beta1.CredentialsValidReason,
+		Message: fmt.Sprintf("Credentials of ansible user %s passed the preflight checks", node.AnsibleUser),
+	}
+
+	secret := &corev1.Secret{}
+	err = r.Client.Get(ctx, client.ObjectKey{Namespace: instance.Namespace, Name: node.AnsiblePasswordSecret}, secret)
+	switch {
+	case k8s_errors.IsNotFound(err):
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = corev1beta1.SecretNotFoundReason
+		condition.Message = fmt.Sprintf("Secret %s holding the ansible password does not exist", node.AnsiblePasswordSecret)
+	case err != nil:
+		return err
+	case node.AnsibleUser == "":
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = corev1beta1.AnsibleUserMissingReason
+		condition.Message = "ansibleUser must be set when ansiblePasswordSecret is used"
+	case len(secret.Data["password"]) == 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = corev1beta1.PasswordMissingReason
+		condition.Message = fmt.Sprintf("Secret %s has no password key or it is empty", node.AnsiblePasswordSecret)
+	}
+
+	meta.SetStatusCondition(&instance.Status.Conditions, condition)
+	return r.Status().Update(ctx, instance)
+}
+
+// kubernetesNodeNamespaceAnnotation records the namespace of the
+// OpenStackDataPlaneNode on the linked Kubernetes Node, as label values
+// can not hold both name and namespace
+const kubernetesNodeNamespaceAnnotation = "dataplane.openstack.org/namespace"
+
+// kubernetesNodeFinalizer is held by linked nodes until the labels and
+// annotation set on the Kubernetes Node have been removed
+const kubernetesNodeFinalizer = "dataplane.openstack.org/kubernetes-node"
+
+// UnlinkKubernetesNodes removes the labels and annotation of the node from
+// every Kubernetes Node carrying them, except for the one named keep
+func (r *OpenStackDataPlaneNodeReconciler) UnlinkKubernetesNodes(ctx context.Context, instance *corev1beta1.OpenStackDataPlaneNode, keep string) error {
+	nodeList := &corev1.NodeList{}
+	err := r.Client.List(ctx, nodeList, client.MatchingLabels{corev1beta1.KubernetesNodeLabel: instance.Name})
+	if err != nil {
+		return err
+	}
+
+	for i := range nodeList.Items {
+		kubeNode := &nodeList.Items[i]
+		if kubeNode.Name == keep || kubeNode.Annotations[kubernetesNodeNamespaceAnnotation] != instance.Namespace {
+			continue
+		}
+		patch := client.MergeFrom(kubeNode.DeepCopy())
+		delete(kubeNode.Labels, corev1beta1.KubernetesNodeLabel)
+		delete(kubeNode.Labels, corev1beta1.KubernetesRoleLabel)
+		delete(kubeNode.Annotations, kubernetesNodeNamespaceAnnotation)
+		err = r.Client.Patch(ctx, kubeNode, patch)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateKubernetesNodeFinalizer adds or removes the finalizer guarding the
+// Kubernetes Node metadata, keeping the status computed so far
+func (r *OpenStackDataPlaneNodeReconciler) updateKubernetesNodeFinalizer(ctx context.Context, instance *corev1beta1.OpenStackDataPlaneNode, linked bool) error {
+	if controllerutil.ContainsFinalizer(instance, kubernetesNodeFinalizer) == linked {
+		return nil
+	}
+
+	// Patch a copy so the status computed so far is kept
+	node := instance.DeepCopy()
+	patch := client.MergeFrom(node.DeepCopy())
+	if linked {
+		controllerutil.AddFinalizer(node, kubernetesNodeFinalizer)
+	} else {
+		controllerutil.RemoveFinalizer(node, kubernetesNodeFinalizer)
+	}
+	err := r.Client.Patch(ctx, node, patch)
+	if err != nil {
+		return err
+	}
+	instance.Finalizers = node.Finalizers
+	instance.ResourceVersion = node.ResourceVersion
+
+	return nil
+}
+
+// unlinkKubernetesNodes removes the node metadata from all Kubernetes Nodes
+// and drops the finalizer once nothing is linked anymore
+func (r *OpenStackDataPlaneNodeReconciler) unlinkKubernetesNodes(ctx context.Context, instance *corev1beta1.OpenStackDataPlaneNode) error {
+	err := r.UnlinkKubernetesNodes(ctx, instance, "")
+	if err != nil {
+		return err
+	}
+	return r.updateKubernetesNodeFinalizer(ctx, instance, false)
+}
+
+// LinkKubernetesNode finds the Kubernetes Node sharing an address with the
+// node, labels it with the node and its role, and records its schedulability
+// in the KubernetesNodeLinked condition. Kubernetes Nodes no longer linked
+// have the labels removed
+func (r *OpenStackDataPlaneNodeReconciler) LinkKubernetesNode(ctx context.Context, instance *corev1beta1.OpenStackDataPlaneNode) error {
+	if !instance.Spec.Node.LinkKubernetesNode {
+		err := r.unlinkKubernetesNodes(ctx, instance)
+		if err != nil {
+			return err
+		}
+		instance.Status.KubernetesNode = ""
+		meta.RemoveStatusCondition(&instance.Status.Conditions, corev1beta1.KubernetesNodeLinkedCondition)
+		return r.Status().Update(ctx, instance)
+	}
+
+	node, err := r.GetEffectiveNode(ctx, instance)
+	if err != nil {
+		return err
+	}
+	addresses := map[string]bool{}
+	for _, address := range []string{node.HostName, node.AnsibleHost, managementIP(node)} {
+		if address != "" {
+			addresses[address] = true
+		}
+	}
+
+	nodeList := &corev1.NodeList{}
+	err = r.Client.List(ctx, nodeList)
+	if err != nil {
+		return err
+	}
+
+	var kubeNode *corev1.Node
+	for i, node := range nodeList.Items {
+		for _, address := range node.Status.Addresses {
+			if addresses[address.Address] {
+				kubeNode = &nodeList.Items[i]
+				break
+			}
+		}
+		if kubeNode != nil {
+			break
+		}
+	}
+
+	if kubeNode == nil {
+		err = r.unlinkKubernetesNodes(ctx, instance)
+		if err != nil {
+			return err
+		}
+		instance.Status.KubernetesNode = ""
+		meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+			Type:    corev1beta1.KubernetesNodeLinkedCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  corev1beta1.KubernetesNodeNotFoundReason,
+			Message: "No Kubernetes Node has an address matching the host name, ansible host or management IP of the node",
+		})
+		return r.Status().Update(ctx, instance)
+	}
+
+	// The finalizer is set before labelling so the metadata is always
+	// removed on deletion, and a Node no longer matching is unlinked
+	err = r.updateKubernetesNodeFinalizer(ctx, instance, true)
+	if err != nil {
+		return err
+	}
+	err = r.UnlinkKubernetesNodes(ctx, instance, kubeNode.Name)
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(kubeNode.DeepCopy())
+	if kubeNode.Labels == nil {
+		kubeNode.Labels = map[string]string{}
+	}
+	if kubeNode.Annotations == nil {
+		kubeNode.Annotations = map[string]string{}
+	}
+	kubeNode.Labels[corev1beta1.KubernetesNodeLabel] = instance.Name
+	kubeNode.Annotations[kubernetesNodeNamespaceAnnotation] = instance.Namespace
+	if instance.Spec.Role != "" {
+		kubeNode.Labels[corev1beta1.KubernetesRoleLabel] = instance.Spec.Role
+	} else {
+		delete(kubeNode.Labels, corev1beta1.KubernetesRoleLabel)
+	}
+	err = r.Client.Patch(ctx, kubeNode, patch)
+	if err != nil {
+		return err
+	}
+
+	instance.Status.KubernetesNode = kubeNode.Name
+	condition := metav1.Condition{
+		Type:    corev1beta1.KubernetesNodeLinkedCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  corev1beta1.KubernetesNodeLinkedReason,
+		Message: fmt.Sprintf("Linked to Kubernetes Node %s", kubeNode.Name),
+	}
+	if kubeNode.Spec.Unschedulable {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = corev1beta1.KubernetesNodeUnschedulableReason
+		condition.Message = fmt.Sprintf("Linked Kubernetes Node %s is unschedulable, disruptive changes are held back", kubeNode.Name)
+	}
+	meta.SetStatusCondition(&instance.Status.Conditions, condition)
+
+	return r.Status().Update(ctx, instance)
+}
+
 func (r *OpenStackDataPlaneNodeReconciler) Provision(ctx context.Context, instance *corev1beta1.OpenStackDataPlaneNode) error {
 	return nil
 }
@@ -117,50 +796,212 @@ type Inventory struct {
 func (r *OpenStackDataPlaneNodeReconciler) GenerateInventory(ctx context.Context, instance *corev1beta1.OpenStackDataPlaneNode) error {
 	var err error
 
-	inventory := make(map[string]map[string]map[string]map[string]string)
-	all := make(map[string]map[string]map[string]string)
-	host := make(map[string]map[string]string)
-	host_vars := make(map[string]string)
+	role, err := r.GetRole(ctx, instance)
+	if err != nil {
+		return err
+	}
+	globalVars, _, err := r.GetGlobalVars(ctx, role)
+	if err != nil {
+		return err
+	}
+	node, err := r.GetEffectiveNode(ctx, instance)
+	if err != nil {
+		return err
+	}
+
+	inventory := make(map[string]map[string]map[string]map[string]interface{})
+	all := make(map[string]map[string]map[string]interface{})
+	host := make(map[string]map[string]interface{})
+	host_vars := make(map[string]interface{})
+	for key, value := range globalVars {
+		host_vars[key] = value
+	}
 	host_vars["ansible_host"] = instance.Spec.Node.HostName
-	host_vars["ansible_user"] = instance.Spec.Node.AnsibleUser
-	host_vars["ansible_port"] = strconv.Itoa(instance.Spec.Node.AnsiblePort)
+	host_vars["ansible_user"] = node.AnsibleUser
+	host_vars["ansible_port"] = strconv.Itoa(node.AnsiblePort)
+	if realtime := node.Realtime; realtime != nil {
+		host_vars["edpm_kernel_package"] = realtime.KernelPackage
+		host_vars["edpm_tuned_profile"] = realtime.TunedProfile
+		host_vars["edpm_tuned_isolated_cores"] = realtime.IsolatedCPUs
+		host_vars["edpm_tuned_housekeeping_cores"] = realtime.HousekeepingCPUs
+		host_vars["edpm_kernel_args"] = fmt.Sprintf("isolcpus=%s nohz_full=%s rcu_nocbs=%s irqaffinity=%s",
+			realtime.IsolatedCPUs, realtime.IsolatedCPUs, realtime.IsolatedCPUs, realtime.HousekeepingCPUs)
+	}
+	if storage := node.Storage; storage != nil {
+		host_vars["edpm_multipathd_enable"] = storage.Multipath
+		host_vars["edpm_iscsid_enable"] = storage.ISCSI
+		host_vars["edpm_fc_enable"] = storage.FibreChannel
+		if len(storage.Targets) > 0 {
+			host_vars["edpm_storage_targets"] = storage.Targets
+		}
+	}
+	if len(node.NFSMounts) > 0 {
+		var mounts []map[string]string
+		for _, mount := range node.NFSMounts {
+			mounts = append(mounts, map[string]string{
+				"src":    fmt.Sprintf("%s:%s", mount.Server, mount.Export),
+				"path":   mount.MountPoint,
+				"opts":   mount.Options,
+				"fstype": "nfs",
+			})
+		}
+		host_vars["edpm_nfs_mounts"] = mounts
+	}
+	if len(node.Physnets) > 0 {
+		var bridgeMappings, vlanRanges []string
+		for _, physnet := range node.Physnets {
+			bridgeMappings = append(bridgeMappings, fmt.Sprintf("%s:%s", physnet.Name, physnet.Bridge))
+			if len(physnet.VLANRanges) == 0 {
+				vlanRanges = append(vlanRanges, physnet.Name)
+			}
+			for _, vlanRange := range physnet.VLANRanges {
+				vlanRanges = append(vlanRanges, fmt.Sprintf("%s:%s", physnet.Name, vlanRange))
+			}
+		}
+		host_vars["edpm_ovn_bridge_mappings"] = bridgeMappings
+		host_vars["edpm_neutron_network_vlan_ranges"] = vlanRanges
+	}
+	if kernel := node.Kernel; kernel != nil {
+		if len(kernel.Sysctls) > 0 {
+			sysctls := make(map[string]map[string]string, len(kernel.Sysctls))
+			for name, value := range kernel.Sysctls {
+				sysctls[name] = map[string]string{"value": value}
+			}
+			host_vars["edpm_kernel_sysctl_extra_settings"] = sysctls
+		}
+		modules := make(map[string]map[string]string)
+		var blacklist []string
+		for _, module := range kernel.Modules {
+			if module.Blacklist {
+				blacklist = append(blacklist, module.Name)
+				continue
+			}
+			modules[module.Name] = map[string]string{"params": module.Options}
+		}
+		if len(modules) > 0 {
+			host_vars["edpm_kernel_extra_modules"] = modules
+		}
+		if len(blacklist) > 0 {
+			host_vars["edpm_kernel_blacklisted_modules"] = blacklist
+		}
+	}
+	for name, image := range node.ContainerImages {
+		host_vars[name] = image
+	}
 	host[instance.Name] = host_vars
 	all["hosts"] = host
 	inventory["all"] = all
 
+	invData, err := yaml.Marshal(inventory)
+	if err != nil {
+		return err
+	}
+
 	configMapName := fmt.Sprintf("dataplanenode-%s-inventory", instance.Name)
 	cm := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      configMapName,
 			Namespace: instance.Namespace,
 		},
+		Data: map[string]string{
+			"inventory": string(invData),
+		},
+	}
+	if role != nil {
+		applyResourceMetadata(cm, role.Spec.ResourceMetadata)
+	}
+	if cm.Labels == nil {
+		cm.Labels = map[string]string{}
+	}
+	cm.Labels[corev1beta1.InventoryNodeLabel] = instance.Name
+	if instance.Spec.Role != "" {
+		cm.Labels[corev1beta1.InventoryRoleLabel] = instance.Spec.Role
 	}
 
-	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, cm, func() error {
-		cm.TypeMeta = metav1.TypeMeta{
-			APIVersion: "v1",
-			Kind:       "ConfigMap",
-		}
-		cm.ObjectMeta = metav1.ObjectMeta{
-			Name:      configMapName,
-			Namespace: instance.Namespace,
-		}
-		invData, err := yaml.Marshal(inventory)
-		if err != nil {
-			return err
-		}
-		cm.Data = map[string]string{
-			"inventory": string(invData),
-		}
-		return nil
-	})
+	err = applyOwned(ctx, r.Client, r.Scheme, instance, cm)
+	if err != nil {
+		return err
+	}
+	err = r.GenerateCredentials(ctx, instance, role, node)
 	if err != nil {
 		return err
 	}
+	meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+		Type:    corev1beta1.InventoryReadyCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  corev1beta1.InventoryGeneratedReason,
+		Message: fmt.Sprintf("Inventory generated in ConfigMap %s", configMapName),
+	})
 
 	return nil
 }
 
+// GenerateCredentials renders the ansible password of the node into the
+// dataplanenode-<name>-credentials Secret, as an inventory holding only
+// ansible_password and ansible_become_password, to be used along with the
+// generated inventory. The Secret carries the resource metadata of the role,
+// which may be nil, and is removed when the node has no password.
+func (r *OpenStackDataPlaneNodeReconciler) GenerateCredentials(ctx context.Context, instance *corev1beta1.OpenStackDataPlaneNode, role *corev1beta1.OpenStackDataPlaneRole, node corev1beta1.NodeSection) error {
+	secretName := fmt.Sprintf("dataplanenode-%s-credentials", instance.Name)
+	if node.AnsiblePasswordSecret == "" {
+		err := r.Client.Delete(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: instance.Namespace},
+		})
+		return client.IgnoreNotFound(err)
+	}
+
+	passwordSecret := &corev1.Secret{}
+	err := r.Client.Get(ctx, client.ObjectKey{Namespace: instance.Namespace, Name: node.AnsiblePasswordSecret}, passwordSecret)
+	if err != nil {
+		return err
+	}
+	password := string(passwordSecret.Data["password"])
+	credentials := map[string]map[string]map[string]map[string]string{
+		"all": {
+			"hosts": {
+				instance.Name: {
+					"ansible_password":        password,
+					"ansible_become_password": password,
+				},
+			},
+		},
+	}
+	credData, err := yaml.Marshal(credentials)
+	if err != nil {
+		return err
+	}
+
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName,
+			Namespace: instance.Namespace,
+		},
+		Data: map[string][]byte{
+			"inventory": credData,
+		},
+	}
+	if role != nil {
+		applyResourceMetadata(secret, role.Spec.ResourceMetadata)
+	}
+	if secret.Labels == nil {
+		secret.Labels = map[string]string{}
+	}
+	secret.Labels[corev1beta1.InventoryNodeLabel] = instance.Name
+	if instance.Spec.Role != "" {
+		secret.Labels[corev1beta1.InventoryRoleLabel] = instance.Spec.Role
+	}
+
+	return applyOwned(ctx, r.Client, r.Scheme, instance, secret)
+}
+
 func (r *OpenStackDataPlaneNodeReconciler) ConfigureNetwork(ctx context.Context, instance *corev1beta1.OpenStackDataPlaneNode) error {
 
 	return nil
@@ -0,0 +1,259 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1beta1 "github.com/openstack-k8s-operators/dataplane-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/dataplane-operator/pkg/testutil"
+)
+
+// newNodeReconciler returns a node reconciler backed by a fake client holding
+// objs
+func newNodeReconciler(t *testing.T, objs ...client.Object) *OpenStackDataPlaneNodeReconciler {
+	t.Helper()
+	scheme, err := testutil.NewScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &OpenStackDataPlaneNodeReconciler{
+		Client: testutil.NewFakeClient(scheme, objs...),
+		Scheme: scheme,
+		Log:    logr.Discard(),
+	}
+}
+
+func TestGenerateCredentialsResourceMetadata(t *testing.T) {
+	ctx := context.Background()
+	role := testutil.NewRole("ns", "compute").Build()
+	role.Spec.ResourceMetadata = corev1beta1.ResourceMetadataSection{
+		Labels: map[string]string{
+			"cost-center":                  "edge",
+			corev1beta1.InventoryNodeLabel: "spoofed",
+		},
+		Annotations: map[string]string{"backup.example.com/include": "true"},
+	}
+	node := testutil.NewNode("ns", "compute-0").WithRole("compute").WithPasswordSecret("password").Build()
+	r := newNodeReconciler(t, role, node, testutil.NewAnsiblePasswordSecret("ns", "password", "secret"))
+
+	err := r.GenerateCredentials(ctx, node, role, node.Spec.Node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret := &corev1.Secret{}
+	err = r.Client.Get(ctx, client.ObjectKey{Namespace: "ns", Name: "dataplanenode-compute-0-credentials"}, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{
+		"cost-center":                  "edge",
+		corev1beta1.InventoryNodeLabel: "compute-0",
+		corev1beta1.InventoryRoleLabel: "compute",
+	} {
+		if got := secret.Labels[key]; got != want {
+			t.Errorf("label %s = %q, want %q", key, got, want)
+		}
+	}
+	if got := secret.Annotations["backup.example.com/include"]; got != "true" {
+		t.Errorf("annotation backup.example.com/include = %q, want true", got)
+	}
+	if len(secret.OwnerReferences) != 1 || secret.OwnerReferences[0].Name != "compute-0" {
+		t.Errorf("unexpected owner references %v", secret.OwnerReferences)
+	}
+}
+
+func TestKubernetesNodeToNodes(t *testing.T) {
+	linked := func(namespace string, name string, fixedIP string) *corev1beta1.OpenStackDataPlaneNode {
+		node := testutil.NewNode(namespace, name).WithNetwork("ctlplane", fixedIP).Build()
+		node.Spec.Node.LinkKubernetesNode = true
+		return node
+	}
+	notLinking := testutil.NewNode("ns-a", "compute-2").WithNetwork("ctlplane", "192.168.122.100").Build()
+	r := newNodeReconciler(t,
+		linked("ns-a", "compute-0", "192.168.122.100"),
+		linked("ns-b", "compute-1", "192.168.122.100"),
+		linked("ns-a", "compute-3", "192.168.122.103"),
+		notLinking,
+	)
+
+	kubeNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "worker-0",
+			Labels:      map[string]string{corev1beta1.KubernetesNodeLabel: "compute-4"},
+			Annotations: map[string]string{kubernetesNodeNamespaceAnnotation: "ns-c"},
+		},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "192.168.122.100"}},
+		},
+	}
+
+	var got []string
+	for _, request := range r.kubernetesNodeToNodes(kubeNode) {
+		got = append(got, request.String())
+	}
+	sort.Strings(got)
+	want := []string{"ns-a/compute-0", "ns-b/compute-1", "ns-c/compute-4"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("requests = %v, want %v", got, want)
+	}
+}
+
+func TestEffectiveNode(t *testing.T) {
+	role := func(template corev1beta1.NodeSection) *corev1beta1.OpenStackDataPlaneRole {
+		return &corev1beta1.OpenStackDataPlaneRole{Spec: corev1beta1.OpenStackDataPlaneRoleSpec{NodeTemplate: template}}
+	}
+	defaults := func(template corev1beta1.NodeSection) *corev1beta1.OpenStackDataPlaneDefaultNodeTemplate {
+		return &corev1beta1.OpenStackDataPlaneDefaultNodeTemplate{
+			Spec: corev1beta1.OpenStackDataPlaneDefaultNodeTemplateSpec{NodeTemplate: template},
+		}
+	}
+	nodeRealtime := &corev1beta1.RealtimeSection{IsolatedCPUs: "2-7", HousekeepingCPUs: "0-1"}
+	roleRealtime := &corev1beta1.RealtimeSection{IsolatedCPUs: "4-15", HousekeepingCPUs: "0-3", TunedProfile: "realtime-virtual-host"}
+	roleStorage := &corev1beta1.StorageSection{Multipath: true}
+	defaultKernel := &corev1beta1.KernelSection{Sysctls: map[string]string{"vm.swappiness": "10"}}
+	rolePhysnets := []corev1beta1.PhysnetSection{{Name: "datacentre", Bridge: "br-ex"}}
+	defaultPhysnets := []corev1beta1.PhysnetSection{{Name: "tenant", Bridge: "br-tenant"}}
+
+	tests := []struct {
+		name     string
+		node     corev1beta1.NodeSection
+		role     *corev1beta1.OpenStackDataPlaneRole
+		defaults *corev1beta1.OpenStackDataPlaneDefaultNodeTemplate
+		expected corev1beta1.NodeSection
+		applied  []string
+	}{
+		{
+			name:     "no templates",
+			node:     corev1beta1.NodeSection{AnsibleUser: "node-user"},
+			expected: corev1beta1.NodeSection{AnsibleUser: "node-user"},
+		},
+		{
+			name:     "scalar fields: node over role over defaults",
+			node:     corev1beta1.NodeSection{AnsibleUser: "node-user"},
+			role:     role(corev1beta1.NodeSection{AnsibleUser: "role-user", AnsiblePort: 2222}),
+			defaults: defaults(corev1beta1.NodeSection{AnsibleUser: "default-user", AnsiblePort: 22, ManagementNetwork: "ctlplane", AnsiblePasswordSecret: "password"}),
+			expected: corev1beta1.NodeSection{AnsibleUser: "node-user", AnsiblePort: 2222, ManagementNetwork: "ctlplane", AnsiblePasswordSecret: "password"},
+			applied:  []string{"ansiblePasswordSecret", "managementNetwork"},
+		},
+		{
+			name:     "sections taken as a whole",
+			node:     corev1beta1.NodeSection{Realtime: nodeRealtime},
+			role:     role(corev1beta1.NodeSection{Realtime: roleRealtime, Storage: roleStorage, Physnets: rolePhysnets}),
+			defaults: defaults(corev1beta1.NodeSection{Kernel: defaultKernel, Physnets: defaultPhysnets}),
+			expected: corev1beta1.NodeSection{Realtime: nodeRealtime, Storage: roleStorage, Physnets: rolePhysnets, Kernel: defaultKernel},
+			applied:  []string{"kernel"},
+		},
+		{
+			name:     "sections from the defaults",
+			defaults: defaults(corev1beta1.NodeSection{Realtime: roleRealtime, Storage: roleStorage, Physnets: defaultPhysnets}),
+			expected: corev1beta1.NodeSection{Realtime: roleRealtime, Storage: roleStorage, Physnets: defaultPhysnets},
+			applied:  []string{"realtime", "storage", "physnets"},
+		},
+		{
+			name: "container images merged per key",
+			node: corev1beta1.NodeSection{ContainerImages: map[string]string{"edpm_nova_compute_image": "nova:canary"}},
+			role: role(corev1beta1.NodeSection{ContainerImages: map[string]string{
+				"edpm_nova_compute_image":   "nova:role",
+				"edpm_ovn_controller_image": "ovn:role",
+			}}),
+			defaults: defaults(corev1beta1.NodeSection{ContainerImages: map[string]string{
+				"edpm_ovn_controller_image":         "ovn:default",
+				"edpm_neutron_metadata_agent_image": "metadata:default",
+				"edpm_iscsid_image":                 "iscsid:default",
+			}}),
+			expected: corev1beta1.NodeSection{ContainerImages: map[string]string{
+				"edpm_nova_compute_image":           "nova:canary",
+				"edpm_ovn_controller_image":         "ovn:role",
+				"edpm_neutron_metadata_agent_image": "metadata:default",
+				"edpm_iscsid_image":                 "iscsid:default",
+			}},
+			applied: []string{"containerImages.edpm_iscsid_image", "containerImages.edpm_neutron_metadata_agent_image"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			node := *test.node.DeepCopy()
+			effective, applied := effectiveNode(test.node, test.role, test.defaults)
+			if !equality.Semantic.DeepEqual(effective, test.expected) {
+				t.Errorf("effective node = %+v, want %+v", effective, test.expected)
+			}
+			if !reflect.DeepEqual(applied, test.applied) {
+				t.Errorf("applied defaults = %v, want %v", applied, test.applied)
+			}
+			if !equality.Semantic.DeepEqual(test.node, node) {
+				t.Errorf("node was modified to %+v", test.node)
+			}
+		})
+	}
+}
+
+// nodeInventoryHostVars runs GenerateInventory for node and returns the host
+// vars of the generated inventory
+func nodeInventoryHostVars(t *testing.T, r *OpenStackDataPlaneNodeReconciler, node *corev1beta1.OpenStackDataPlaneNode) map[string]interface{} {
+	t.Helper()
+	ctx := context.Background()
+	err := r.GenerateInventory(ctx, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cm := &corev1.ConfigMap{}
+	err = r.Client.Get(ctx, client.ObjectKey{Namespace: node.Namespace, Name: "dataplanenode-" + node.Name + "-inventory"}, cm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inventory := map[string]map[string]map[string]map[string]interface{}{}
+	err = yaml.Unmarshal([]byte(cm.Data["inventory"]), &inventory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return inventory["all"]["hosts"][node.Name]
+}
+
+func TestGenerateInventoryRealtime(t *testing.T) {
+	node := testutil.NewNode("ns", "compute-0").Build()
+	node.Spec.Node.Realtime = &corev1beta1.RealtimeSection{
+		KernelPackage:    "kernel-rt",
+		TunedProfile:     "realtime-virtual-host",
+		IsolatedCPUs:     "2-7",
+		HousekeepingCPUs: "0-1",
+	}
+	r := newNodeReconciler(t, node)
+
+	hostVars := nodeInventoryHostVars(t, r, node)
+	for key, want := range map[string]string{
+		"edpm_tuned_isolated_cores":     "2-7",
+		"edpm_tuned_housekeeping_cores": "0-1",
+		"edpm_kernel_args":              "isolcpus=2-7 nohz_full=2-7 rcu_nocbs=2-7 irqaffinity=0-1",
+	} {
+		if got := hostVars[key]; got != want {
+			t.Errorf("%s = %v, want %q", key, got, want)
+		}
+	}
+}
@@ -18,12 +18,27 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
 
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	k8syaml "sigs.k8s.io/yaml"
 
 	corev1beta1 "github.com/openstack-k8s-operators/dataplane-operator/api/v1beta1"
 )
@@ -37,6 +52,9 @@ type OpenStackDataPlaneRoleReconciler struct {
 //+kubebuilder:rbac:groups=core.openstack.org,resources=openstackdataplaneroles,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core.openstack.org,resources=openstackdataplaneroles/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=core.openstack.org,resources=openstackdataplaneroles/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core.openstack.org,resources=openstackdataplanenodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core.openstack.org,resources=openstackdataplanedefaultnodetemplates,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -66,6 +84,47 @@ func (r *OpenStackDataPlaneRoleReconciler) Reconcile(ctx context.Context, req ct
 
 	r.ReconcileNodes(ctx, instance)
 
+	nodes, err := r.GetRoleNodes(ctx, instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	settings, err := r.GetEffectiveNodes(ctx, instance, nodes)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	evaluated := readinessNodes(instance, nodes)
+	r.AggregateAnsibleCredentials(instance, evaluated)
+	r.CheckPhysnetsConsistency(instance, evaluated, settings)
+	r.EvaluateReadiness(instance, evaluated)
+
+	template, err := r.GetEffectiveTemplate(ctx, instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	instance.Status.ImageOverrides = imageOverrides(template, nodes)
+
+	err = r.AuditOrphans(ctx, instance, nodes)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.GenerateInventory(ctx, instance, nodes, settings)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.GenerateStatusDocument(ctx, instance, nodes, template, settings)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.Status().Update(ctx, instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	return ctrl.Result{}, nil
 }
 
@@ -73,9 +132,151 @@ func (r *OpenStackDataPlaneRoleReconciler) Reconcile(ctx context.Context, req ct
 func (r *OpenStackDataPlaneRoleReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&corev1beta1.OpenStackDataPlaneRole{}).
+		Owns(&corev1.ConfigMap{}).
+		Watches(&source.Kind{Type: &corev1beta1.OpenStackDataPlaneNode{}},
+			handler.EnqueueRequestsFromMapFunc(nodeToRole)).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(nodeInventoryToRole)).
+		Watches(&source.Kind{Type: &corev1beta1.OpenStackDataPlaneDefaultNodeTemplate{}},
+			handler.EnqueueRequestsFromMapFunc(r.defaultNodeTemplateToRoles)).
 		Complete(r)
 }
 
+// defaultNodeTemplateToRoles maps the default node template of a namespace to
+// reconcile requests of all roles of the namespace
+func (r *OpenStackDataPlaneRoleReconciler) defaultNodeTemplateToRoles(obj client.Object) []reconcile.Request {
+	if obj.GetName() != corev1beta1.DefaultNodeTemplateName {
+		return nil
+	}
+
+	roleList := &corev1beta1.OpenStackDataPlaneRoleList{}
+	err := r.Client.List(context.Background(), roleList, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		log.Log.Error(err, fmt.Sprintf("Unable to list roles using OpenStackDataPlaneDefaultNodeTemplate %s", obj.GetName()))
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(roleList.Items))
+	for _, role := range roleList.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKey{Namespace: role.Namespace, Name: role.Name},
+		})
+	}
+	return requests
+}
+
+// nodeToRole maps an OpenStackDataPlaneNode to a reconcile request of the
+// role it belongs to
+func nodeToRole(obj client.Object) []reconcile.Request {
+	node, ok := obj.(*corev1beta1.OpenStackDataPlaneNode)
+	if !ok || node.Spec.Role == "" {
+		return nil
+	}
+	return []reconcile.Request{
+		{NamespacedName: client.ObjectKey{Namespace: node.Namespace, Name: node.Spec.Role}},
+	}
+}
+
+// nodeInventoryToRole maps the generated inventory of a node to a reconcile
+// request of the role it is labelled with, so that the dynamic inventory of
+// the role follows the host vars of its nodes
+func nodeInventoryToRole(obj client.Object) []reconcile.Request {
+	role, found := obj.GetLabels()[corev1beta1.InventoryRoleLabel]
+	if !found || role == "" {
+		return nil
+	}
+	return []reconcile.Request{
+		{NamespacedName: client.ObjectKey{Namespace: obj.GetNamespace(), Name: role}},
+	}
+}
+
+// GetRoleNodes returns the OpenStackDataPlaneNodes that belong to the role,
+// sorted by name
+func (r *OpenStackDataPlaneRoleReconciler) GetRoleNodes(ctx context.Context, instance *corev1beta1.OpenStackDataPlaneRole) ([]corev1beta1.OpenStackDataPlaneNode, error) {
+	nodeList := &corev1beta1.OpenStackDataPlaneNodeList{}
+	err := r.Client.List(ctx, nodeList, client.InNamespace(instance.Namespace))
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []corev1beta1.OpenStackDataPlaneNode
+	for _, node := range nodeList.Items {
+		if node.Spec.Role == instance.Name {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+
+	return nodes, nil
+}
+
+// GetEffectiveNodes returns the settings of every node of the role layered
+// over the node template of the role and the default node template of the
+// namespace, by node name
+func (r *OpenStackDataPlaneRoleReconciler) GetEffectiveNodes(ctx context.Context, instance *corev1beta1.OpenStackDataPlaneRole, nodes []corev1beta1.OpenStackDataPlaneNode) (map[string]corev1beta1.NodeSection, error) {
+	defaults, err := getDefaultNodeTemplate(ctx, r.Client, instance.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make(map[string]corev1beta1.NodeSection, len(nodes))
+	for _, node := range nodes {
+		settings[node.Name], _ = effectiveNode(node.Spec.Node, instance, defaults)
+	}
+
+	return settings, nil
+}
+
+// GetEffectiveTemplate returns the node template of the role layered over the
+// default node template of the namespace, i.e. the settings a node gets when
+// it does not set any itself
+func (r *OpenStackDataPlaneRoleReconciler) GetEffectiveTemplate(ctx context.Context, instance *corev1beta1.OpenStackDataPlaneRole) (corev1beta1.NodeSection, error) {
+	defaults, err := getDefaultNodeTemplate(ctx, r.Client, instance.Namespace)
+	if err != nil {
+		return instance.Spec.NodeTemplate, err
+	}
+	template, _ := effectiveNode(instance.Spec.NodeTemplate, nil, defaults)
+
+	return template, nil
+}
+
+// AggregateAnsibleCredentials collects the ansible credential preflight
+// failures of all nodes of the role into a single condition
+func (r *OpenStackDataPlaneRoleReconciler) AggregateAnsibleCredentials(instance *corev1beta1.OpenStackDataPlaneRole, nodes []corev1beta1.OpenStackDataPlaneNode) {
+	checked := 0
+	var failures []string
+	for _, node := range nodes {
+		cond := meta.FindStatusCondition(node.Status.Conditions, corev1beta1.AnsibleCredentialsCondition)
+		if cond == nil {
+			continue
+		}
+		checked++
+		if cond.Status != metav1.ConditionTrue {
+			failures = append(failures, fmt.Sprintf("%s: %s", node.Name, cond.Message))
+		}
+	}
+
+	switch {
+	case checked == 0:
+		meta.RemoveStatusCondition(&instance.Status.Conditions, corev1beta1.AnsibleCredentialsCondition)
+	case len(failures) > 0:
+		meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+			Type:   corev1beta1.AnsibleCredentialsCondition,
+			Status: metav1.ConditionFalse,
+			Reason: corev1beta1.CredentialsInvalidReason,
+			Message: fmt.Sprintf("%d of %d nodes failed the ansible credentials preflight: %s",
+				len(failures), checked, strings.Join(failures, "; ")),
+		})
+	default:
+		meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+			Type:    corev1beta1.AnsibleCredentialsCondition,
+			Status:  metav1.ConditionTrue,
+			Reason:  corev1beta1.CredentialsValidReason,
+			Message: fmt.Sprintf("All %d nodes passed the ansible credentials preflight", checked),
+		})
+	}
+}
+
 func (r *OpenStackDataPlaneRoleReconciler) ReconcileNodes(ctx context.Context, instance *corev1beta1.OpenStackDataPlaneRole) error {
 	// loop over r.Spec.DataPlaneNodes:
 	//   for each node:
@@ -85,3 +286,505 @@ func (r *OpenStackDataPlaneRoleReconciler) ReconcileNodes(ctx context.Context, i
 
 	return nil
 }
+
+// CheckPhysnetsConsistency verifies that all nodes of the role map their
+// physical networks the same way, as Neutron expects every node of a role to
+// provide the same physical networks. The physical networks are taken from the
+// layered settings of the nodes, so those set on the node template count too
+func (r *OpenStackDataPlaneRoleReconciler) CheckPhysnetsConsistency(instance *corev1beta1.OpenStackDataPlaneRole, nodes []corev1beta1.OpenStackDataPlaneNode, settings map[string]corev1beta1.NodeSection) {
+	var reference *corev1beta1.OpenStackDataPlaneNode
+	var mismatches []string
+	for i, node := range nodes {
+		if len(settings[node.Name].Physnets) == 0 {
+			continue
+		}
+		if reference == nil {
+			reference = &nodes[i]
+			continue
+		}
+		if !equality.Semantic.DeepEqual(physnetsByName(settings[reference.Name].Physnets), physnetsByName(settings[node.Name].Physnets)) {
+			mismatches = append(mismatches, node.Name)
+		}
+	}
+
+	switch {
+	case reference == nil:
+		meta.RemoveStatusCondition(&instance.Status.Conditions, corev1beta1.PhysnetsConsistentCondition)
+	case len(mismatches) > 0:
+		meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+			Type:   corev1beta1.PhysnetsConsistentCondition,
+			Status: metav1.ConditionFalse,
+			Reason: corev1beta1.PhysnetsMismatchReason,
+			Message: fmt.Sprintf("Physical networks of nodes %s differ from those of node %s",
+				strings.Join(mismatches, ", "), reference.Name),
+		})
+	default:
+		meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+			Type:    corev1beta1.PhysnetsConsistentCondition,
+			Status:  metav1.ConditionTrue,
+			Reason:  corev1beta1.PhysnetsMatchReason,
+			Message: "All nodes map their physical networks to the same bridges and VLAN ranges",
+		})
+	}
+}
+
+// physnetsByName indexes physnets by name, ignoring the host interface which
+// may legitimately differ between nodes
+func physnetsByName(physnets []corev1beta1.PhysnetSection) map[string]corev1beta1.PhysnetSection {
+	byName := make(map[string]corev1beta1.PhysnetSection, len(physnets))
+	for _, physnet := range physnets {
+		physnet.Interface = ""
+		byName[physnet.Name] = physnet
+	}
+	return byName
+}
+
+// inMaintenance reports whether the node is in maintenance, either as
+// requested in its spec or as last recorded in its status
+func inMaintenance(node corev1beta1.OpenStackDataPlaneNode) bool {
+	return node.Spec.Node.Maintenance ||
+		meta.IsStatusConditionTrue(node.Status.Conditions, corev1beta1.NodeMaintenanceCondition)
+}
+
+// readinessNodes returns the nodes the conditions of the role are computed
+// from, leaving out those in maintenance unless the readiness policy includes
+// them
+func readinessNodes(instance *corev1beta1.OpenStackDataPlaneRole, nodes []corev1beta1.OpenStackDataPlaneNode) []corev1beta1.OpenStackDataPlaneNode {
+	if instance.Spec.ReadinessPolicy.IncludeNodesInMaintenance {
+		return nodes
+	}
+	return activeNodes(nodes)
+}
+
+// activeNodes returns the nodes that are not in maintenance
+func activeNodes(nodes []corev1beta1.OpenStackDataPlaneNode) []corev1beta1.OpenStackDataPlaneNode {
+	var active []corev1beta1.OpenStackDataPlaneNode
+	for _, node := range nodes {
+		if !inMaintenance(node) {
+			active = append(active, node)
+		}
+	}
+
+	return active
+}
+
+// EvaluateReadiness sets the Ready condition of the role according to its
+// readiness policy
+func (r *OpenStackDataPlaneRoleReconciler) EvaluateReadiness(instance *corev1beta1.OpenStackDataPlaneRole, nodes []corev1beta1.OpenStackDataPlaneNode) {
+	policy := instance.Spec.ReadinessPolicy
+	required := policy.RequiredConditions
+	if len(required) == 0 {
+		required = corev1beta1.DefaultReadinessConditions
+	}
+
+	var unmet []string
+	for _, conditionType := range required {
+		if meta.IsStatusConditionFalse(instance.Status.Conditions, conditionType) {
+			unmet = append(unmet, fmt.Sprintf("role: %s", conditionType))
+		}
+	}
+	for _, node := range nodes {
+		for _, conditionType := range required {
+			if meta.IsStatusConditionFalse(node.Status.Conditions, conditionType) {
+				unmet = append(unmet, fmt.Sprintf("%s: %s", node.Name, conditionType))
+			}
+		}
+	}
+
+	if len(unmet) > 0 {
+		meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+			Type:    corev1beta1.ReadyCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  corev1beta1.RequiredConditionsNotMetReason,
+			Message: fmt.Sprintf("Required conditions are not met: %s", strings.Join(unmet, "; ")),
+		})
+		return
+	}
+	meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+		Type:    corev1beta1.ReadyCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  corev1beta1.ReadyReason,
+		Message: fmt.Sprintf("All required conditions are met: %s", strings.Join(required, ", ")),
+	})
+}
+
+// AuditOrphans lists the node inventories labelled with the role whose owning
+// OpenStackDataPlaneNode is not one of its current nodes, e.g. because the
+// node moved to another role, and deletes them when a repair is requested.
+// ConfigMaps not controlled by an OpenStackDataPlaneNode are never touched
+func (r *OpenStackDataPlaneRoleReconciler) AuditOrphans(ctx context.Context, instance *corev1beta1.OpenStackDataPlaneRole, nodes []corev1beta1.OpenStackDataPlaneNode) error {
+	current := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		current[node.Name] = true
+	}
+
+	cmList := &corev1.ConfigMapList{}
+	err := r.Client.List(ctx, cmList, client.InNamespace(instance.Namespace),
+		client.MatchingLabels{corev1beta1.InventoryRoleLabel: instance.Name})
+	if err != nil {
+		return err
+	}
+
+	_, repairRequested := instance.Annotations[corev1beta1.RepairOrphansAnnotation]
+	repair := instance.Spec.AutoRepairOrphans || repairRequested
+
+	var orphans []string
+	for i, cm := range cmList.Items {
+		// Only inventories generated for a node are audited, other
+		// ConfigMaps carrying the label are left alone
+		owner := metav1.GetControllerOf(&cmList.Items[i])
+		if owner == nil || owner.Kind != "OpenStackDataPlaneNode" ||
+			!strings.HasPrefix(owner.APIVersion, corev1beta1.GroupVersion.Group+"/") {
+			continue
+		}
+		if current[owner.Name] {
+			continue
+		}
+		if repair {
+			err = r.Client.Delete(ctx, &cmList.Items[i])
+			if err != nil && !k8s_errors.IsNotFound(err) {
+				return err
+			}
+			continue
+		}
+		orphans = append(orphans, fmt.Sprintf("ConfigMap/%s", cm.Name))
+	}
+	instance.Status.OrphanedResources = orphans
+
+	if repairRequested {
+		// Patch a copy so the status computed so far is kept
+		role := instance.DeepCopy()
+		patch := client.MergeFrom(role.DeepCopy())
+		delete(role.Annotations, corev1beta1.RepairOrphansAnnotation)
+		err = r.Client.Patch(ctx, role, patch)
+		if err != nil {
+			return err
+		}
+		instance.Annotations = role.Annotations
+		instance.ResourceVersion = role.ResourceVersion
+	}
+
+	return nil
+}
+
+// GenerateInventory renders the group level inventory of the role, listing
+// its nodes and the variables shared by them, along with a dynamic inventory
+// of the role and a script serving it. Nodes in maintenance are left out, so
+// that they are neither configured nor listed as peers of the other nodes
+func (r *OpenStackDataPlaneRoleReconciler) GenerateInventory(ctx context.Context, instance *corev1beta1.OpenStackDataPlaneRole, nodes []corev1beta1.OpenStackDataPlaneNode, settings map[string]corev1beta1.NodeSection) error {
+	nodes = activeNodes(nodes)
+	hosts := make(map[string]map[string]interface{})
+	for _, node := range nodes {
+		hosts[node.Name] = map[string]interface{}{}
+	}
+	group_vars := make(map[string]interface{})
+	group_vars["edpm_role_peers"] = rolePeers(nodes)
+	if instance.Spec.ManageEtcHosts {
+		group_vars["edpm_etc_hosts_fragment"] = etcHostsFragment(nodes, settings)
+	}
+	inventory := map[string]map[string]interface{}{
+		"all": {
+			"hosts": hosts,
+			"vars":  group_vars,
+		},
+	}
+
+	invData, err := yaml.Marshal(inventory)
+	if err != nil {
+		return err
+	}
+	dynamicInvData, err := r.DynamicInventory(ctx, instance, nodes, group_vars)
+	if err != nil {
+		return err
+	}
+
+	configMapName := fmt.Sprintf("dataplanerole-%s-inventory", instance.Name)
+	cm := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configMapName,
+			Namespace: instance.Namespace,
+		},
+		Data: map[string]string{
+			"inventory":      string(invData),
+			"inventory.json": string(dynamicInvData),
+			"inventory.sh":   dynamicInventoryScript,
+		},
+	}
+	applyResourceMetadata(cm, instance.Spec.ResourceMetadata)
+
+	return applyOwned(ctx, r.Client, r.Scheme, instance, cm)
+}
+
+// dynamicInventoryScript prints inventory.json from the same directory. It
+// answers --list, and --host is never called as the inventory has _meta.
+const dynamicInventoryScript = `#!/bin/sh
+if [ "$1" = "--host" ]; then
+    echo '{}'
+else
+    cat "$(dirname "$0")/inventory.json"
+fi
+`
+
+// DynamicInventory renders the inventory of the role in the JSON format of
+// ansible dynamic inventory scripts, with the host vars of every node taken
+// from its generated inventory, so that external ansible runs and AWX/AAP can
+// use the operator as their inventory source. The nodes are listed in a group
+// named after the role, which the role webhook keeps from clashing with the
+// all, ungrouped and _meta keys
+func (r *OpenStackDataPlaneRoleReconciler) DynamicInventory(ctx context.Context, instance *corev1beta1.OpenStackDataPlaneRole, nodes []corev1beta1.OpenStackDataPlaneNode, groupVars map[string]interface{}) ([]byte, error) {
+	hostNames := make([]string, 0, len(nodes))
+	hostVars := make(map[string]interface{}, len(nodes))
+	for _, node := range nodes {
+		hostNames = append(hostNames, node.Name)
+		hostVars[node.Name] = map[string]interface{}{}
+
+		cm := &corev1.ConfigMap{}
+		err := r.Client.Get(ctx, client.ObjectKey{Namespace: node.Namespace, Name: fmt.Sprintf("dataplanenode-%s-inventory", node.Name)}, cm)
+		if k8s_errors.IsNotFound(err) {
+			// Not generated yet, the role is reconciled again once it is
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		invJSON, err := k8syaml.YAMLToJSON([]byte(cm.Data["inventory"]))
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse inventory of node %s: %w", node.Name, err)
+		}
+		nodeInventory := struct {
+			All struct {
+				Hosts map[string]interface{} `json:"hosts"`
+			} `json:"all"`
+		}{}
+		err = json.Unmarshal(invJSON, &nodeInventory)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse inventory of node %s: %w", node.Name, err)
+		}
+		if vars, found := nodeInventory.All.Hosts[node.Name]; found {
+			hostVars[node.Name] = vars
+		}
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"all": map[string]interface{}{
+			"children": []string{instance.Name},
+		},
+		instance.Name: map[string]interface{}{
+			"hosts": hostNames,
+			"vars":  groupVars,
+		},
+		"_meta": map[string]interface{}{
+			"hostvars": hostVars,
+		},
+	})
+}
+
+// imageOverrides lists the container images of the nodes that diverge from
+// the effective node template of the role, ordered by node and variable name
+func imageOverrides(template corev1beta1.NodeSection, nodes []corev1beta1.OpenStackDataPlaneNode) []string {
+	var overrides []string
+	for _, node := range nodes {
+		images := nodeImageOverrides(template, node)
+		names := make([]string, 0, len(images))
+		for name := range images {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			overrides = append(overrides, fmt.Sprintf("%s: %s=%s", node.Name, name, images[name]))
+		}
+	}
+
+	return overrides
+}
+
+// nodeImageOverrides returns the container images of a node that differ from
+// the effective node template of the role
+func nodeImageOverrides(template corev1beta1.NodeSection, node corev1beta1.OpenStackDataPlaneNode) map[string]string {
+	var images map[string]string
+	for name, image := range node.Spec.Node.ContainerImages {
+		if template.ContainerImages[name] == image {
+			continue
+		}
+		if images == nil {
+			images = make(map[string]string)
+		}
+		images[name] = image
+	}
+
+	return images
+}
+
+// roleStatusDocument is the JSON summary of a role published for dashboards
+type roleStatusDocument struct {
+	Role              string               `json:"role"`
+	Ready             bool                 `json:"ready"`
+	Message           string               `json:"message,omitempty"`
+	Nodes             []nodeStatusDocument `json:"nodes"`
+	OrphanedResources []string             `json:"orphanedResources,omitempty"`
+}
+
+// nodeStatusDocument is the JSON summary of a node of a role
+type nodeStatusDocument struct {
+	Name           string            `json:"name"`
+	HostName       string            `json:"hostName"`
+	ManagementIP   string            `json:"managementIP,omitempty"`
+	Maintenance    bool              `json:"maintenance"`
+	KubernetesNode string            `json:"kubernetesNode,omitempty"`
+	Conditions     map[string]string `json:"conditions,omitempty"`
+	ImageOverrides map[string]string `json:"imageOverrides,omitempty"`
+}
+
+// GenerateStatusDocument publishes a compact JSON summary of the role and its
+// nodes in the status.json key of the dataplanerole-<name>-status ConfigMap,
+// for dashboards that should not need to parse the CRDs. It holds no
+// timestamps so that it is only rewritten when the summary changes.
+func (r *OpenStackDataPlaneRoleReconciler) GenerateStatusDocument(ctx context.Context, instance *corev1beta1.OpenStackDataPlaneRole, nodes []corev1beta1.OpenStackDataPlaneNode, template corev1beta1.NodeSection, settings map[string]corev1beta1.NodeSection) error {
+	doc := roleStatusDocument{
+		Role:              instance.Name,
+		Nodes:             []nodeStatusDocument{},
+		OrphanedResources: instance.Status.OrphanedResources,
+	}
+	if ready := meta.FindStatusCondition(instance.Status.Conditions, corev1beta1.ReadyCondition); ready != nil {
+		doc.Ready = ready.Status == metav1.ConditionTrue
+		doc.Message = ready.Message
+	}
+	for _, node := range nodes {
+		nodeDoc := nodeStatusDocument{
+			Name:           node.Name,
+			HostName:       node.Spec.Node.HostName,
+			ManagementIP:   managementIP(settings[node.Name]),
+			Maintenance:    node.Spec.Node.Maintenance,
+			KubernetesNode: node.Status.KubernetesNode,
+			ImageOverrides: nodeImageOverrides(template, node),
+		}
+		for _, condition := range node.Status.Conditions {
+			if nodeDoc.Conditions == nil {
+				nodeDoc.Conditions = make(map[string]string)
+			}
+			nodeDoc.Conditions[condition.Type] = string(condition.Status)
+		}
+		doc.Nodes = append(doc.Nodes, nodeDoc)
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	cm := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("dataplanerole-%s-status", instance.Name),
+			Namespace: instance.Namespace,
+		},
+		Data: map[string]string{
+			"status.json": string(data),
+		},
+	}
+	applyResourceMetadata(cm, instance.Spec.ResourceMetadata)
+
+	return applyOwned(ctx, r.Client, r.Scheme, instance, cm)
+}
+
+// rolePeers lists the host name and IP of every node of the role per
+// network, ordered by node name, for services that need to know their peers
+func rolePeers(nodes []corev1beta1.OpenStackDataPlaneNode) map[string][]map[string]string {
+	peers := make(map[string][]map[string]string)
+	for _, node := range nodes {
+		for _, network := range node.Spec.Node.Networks {
+			if network.FixedIP == "" {
+				continue
+			}
+			peers[network.Network] = append(peers[network.Network], map[string]string{
+				"name":      node.Name,
+				"host_name": node.Spec.Node.HostName,
+				"ip":        network.FixedIP,
+			})
+		}
+	}
+	return peers
+}
+
+// etcHostsFragment renders one /etc/hosts line per node, mapping the
+// management IP of the node, taken from its layered settings, to its host
+// name and short name
+func etcHostsFragment(nodes []corev1beta1.OpenStackDataPlaneNode, settings map[string]corev1beta1.NodeSection) string {
+	var lines []string
+	for _, node := range nodes {
+		hostName := node.Spec.Node.HostName
+		ip := managementIP(settings[node.Name])
+		if hostName == "" || ip == "" {
+			continue
+		}
+		names := hostName
+		if short := strings.SplitN(hostName, ".", 2)[0]; short != hostName {
+			names = fmt.Sprintf("%s %s", hostName, short)
+		}
+		lines = append(lines, fmt.Sprintf("%s %s", ip, names))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// managementIP returns the IP the node is reached on: the fixed IP of its
+// management network, or its ansible host when that is an IP address
+func managementIP(node corev1beta1.NodeSection) string {
+	for _, network := range node.Networks {
+		if network.Network == node.ManagementNetwork && network.FixedIP != "" {
+			return network.FixedIP
+		}
+	}
+	if net.ParseIP(node.AnsibleHost) != nil {
+		return node.AnsibleHost
+	}
+
+	return ""
+}
+
+// fieldOwner is the field manager of the server-side applies of the operator
+const fieldOwner = client.FieldOwner("dataplane-operator")
+
+// applyOwned server-side applies obj, which must hold the complete desired
+// state of the generated resource, with owner as its controller. Fields
+// dropped from obj since the previous apply are removed, while fields set by
+// other managers are left alone.
+func applyOwned(ctx context.Context, c client.Client, scheme *runtime.Scheme, owner client.Object, obj client.Object) error {
+	if err := controllerutil.SetControllerReference(owner, obj, scheme); err != nil {
+		return err
+	}
+	return c.Patch(ctx, obj, client.Apply, fieldOwner, client.ForceOwnership)
+}
+
+// applyResourceMetadata adds the labels and annotations requested by the role
+// to a generated resource. It must be called before the operator sets its own
+// labels, which take precedence.
+func applyResourceMetadata(obj metav1.Object, metadata corev1beta1.ResourceMetadataSection) {
+	if len(metadata.Labels) > 0 {
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = make(map[string]string, len(metadata.Labels))
+		}
+		for key, value := range metadata.Labels {
+			labels[key] = value
+		}
+		obj.SetLabels(labels)
+	}
+	if len(metadata.Annotations) > 0 {
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string, len(metadata.Annotations))
+		}
+		for key, value := range metadata.Annotations {
+			annotations[key] = value
+		}
+		obj.SetAnnotations(annotations)
+	}
+}
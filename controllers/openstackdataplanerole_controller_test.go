@@ -0,0 +1,311 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1beta1 "github.com/openstack-k8s-operators/dataplane-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/dataplane-operator/pkg/testutil"
+)
+
+func TestEvaluateReadiness(t *testing.T) {
+	linkFailed := testutil.NewNode("ns", "compute-0").
+		WithCondition(corev1beta1.KubernetesNodeLinkedCondition, metav1.ConditionFalse, corev1beta1.KubernetesNodeNotFoundReason)
+	maintained := testutil.NewNode("ns", "compute-3").
+		WithCondition(corev1beta1.KubernetesNodeLinkedCondition, metav1.ConditionFalse, corev1beta1.KubernetesNodeNotFoundReason).
+		InMaintenance()
+	notDeployed := testutil.NewNode("ns", "compute-1").
+		WithCondition(corev1beta1.InventoryReadyCondition, metav1.ConditionFalse, "Pending")
+	healthy := testutil.NewNode("ns", "compute-2").
+		WithCondition(corev1beta1.AnsibleCredentialsCondition, metav1.ConditionTrue, corev1beta1.CredentialsValidReason)
+
+	tests := []struct {
+		name               string
+		required           []string
+		includeMaintenance bool
+		roleConditions     []metav1.Condition
+		nodes              []*corev1beta1.OpenStackDataPlaneNode
+		ready              metav1.ConditionStatus
+	}{
+		{
+			name:  "default set met",
+			nodes: []*corev1beta1.OpenStackDataPlaneNode{healthy.Build(), notDeployed.Build()},
+			ready: metav1.ConditionTrue,
+		},
+		{
+			name:  "default set node condition false",
+			nodes: []*corev1beta1.OpenStackDataPlaneNode{healthy.Build(), linkFailed.Build()},
+			ready: metav1.ConditionFalse,
+		},
+		{
+			name: "default set role condition false",
+			roleConditions: []metav1.Condition{{
+				Type:   corev1beta1.PhysnetsConsistentCondition,
+				Status: metav1.ConditionFalse,
+				Reason: corev1beta1.PhysnetsMismatchReason,
+			}},
+			nodes: []*corev1beta1.OpenStackDataPlaneNode{healthy.Build()},
+			ready: metav1.ConditionFalse,
+		},
+		{
+			name:     "custom set ignores other conditions",
+			required: []string{corev1beta1.AnsibleCredentialsCondition},
+			nodes:    []*corev1beta1.OpenStackDataPlaneNode{healthy.Build(), linkFailed.Build()},
+			ready:    metav1.ConditionTrue,
+		},
+		{
+			name:     "custom set condition false",
+			required: []string{corev1beta1.InventoryReadyCondition},
+			nodes:    []*corev1beta1.OpenStackDataPlaneNode{healthy.Build(), notDeployed.Build()},
+			ready:    metav1.ConditionFalse,
+		},
+		{
+			name:  "node in maintenance excluded",
+			nodes: []*corev1beta1.OpenStackDataPlaneNode{healthy.Build(), maintained.Build()},
+			ready: metav1.ConditionTrue,
+		},
+		{
+			name:               "node in maintenance included",
+			includeMaintenance: true,
+			nodes:              []*corev1beta1.OpenStackDataPlaneNode{healthy.Build(), maintained.Build()},
+			ready:              metav1.ConditionFalse,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			role := testutil.NewRole("ns", "compute").WithReadinessPolicy(test.required...).Build()
+			role.Spec.ReadinessPolicy.IncludeNodesInMaintenance = test.includeMaintenance
+			role.Status.Conditions = test.roleConditions
+			var nodes []corev1beta1.OpenStackDataPlaneNode
+			for _, node := range test.nodes {
+				nodes = append(nodes, *node)
+			}
+
+			r := &OpenStackDataPlaneRoleReconciler{}
+			r.EvaluateReadiness(role, readinessNodes(role, nodes))
+
+			ready := meta.FindStatusCondition(role.Status.Conditions, corev1beta1.ReadyCondition)
+			if ready == nil {
+				t.Fatal("Ready condition not set")
+			}
+			if ready.Status != test.ready {
+				t.Errorf("Ready = %s (%s), want %s", ready.Status, ready.Message, test.ready)
+			}
+		})
+	}
+}
+
+func TestCheckPhysnetsConsistency(t *testing.T) {
+	physnets := func(bridge string, iface string) []corev1beta1.PhysnetSection {
+		return []corev1beta1.PhysnetSection{{Name: "datacentre", Bridge: bridge, Interface: iface, VLANRanges: []string{"1:100"}}}
+	}
+
+	tests := []struct {
+		name     string
+		settings map[string]corev1beta1.NodeSection
+		status   metav1.ConditionStatus
+	}{
+		{
+			name:     "no physnets",
+			settings: map[string]corev1beta1.NodeSection{},
+		},
+		{
+			name: "same mapping on another interface",
+			settings: map[string]corev1beta1.NodeSection{
+				"compute-0": {Physnets: physnets("br-ex", "eth1")},
+				"compute-1": {Physnets: physnets("br-ex", "eno2")},
+			},
+			status: metav1.ConditionTrue,
+		},
+		{
+			name: "bridge mismatch",
+			settings: map[string]corev1beta1.NodeSection{
+				"compute-0": {Physnets: physnets("br-ex", "eth1")},
+				"compute-1": {Physnets: physnets("br-datacentre", "eth1")},
+			},
+			status: metav1.ConditionFalse,
+		},
+		{
+			name: "node without physnets ignored",
+			settings: map[string]corev1beta1.NodeSection{
+				"compute-0": {Physnets: physnets("br-ex", "eth1")},
+			},
+			status: metav1.ConditionTrue,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			role := testutil.NewRole("ns", "compute").Build()
+			nodes := []corev1beta1.OpenStackDataPlaneNode{
+				*testutil.NewNode("ns", "compute-0").Build(),
+				*testutil.NewNode("ns", "compute-1").Build(),
+			}
+
+			r := &OpenStackDataPlaneRoleReconciler{}
+			r.CheckPhysnetsConsistency(role, nodes, test.settings)
+
+			condition := meta.FindStatusCondition(role.Status.Conditions, corev1beta1.PhysnetsConsistentCondition)
+			if test.status == "" {
+				if condition != nil {
+					t.Errorf("expected no PhysnetsConsistent condition, got %s", condition.Status)
+				}
+				return
+			}
+			if condition == nil {
+				t.Fatal("PhysnetsConsistent condition not set")
+			}
+			if condition.Status != test.status {
+				t.Errorf("PhysnetsConsistent = %s (%s), want %s", condition.Status, condition.Message, test.status)
+			}
+		})
+	}
+}
+
+func TestImageOverrides(t *testing.T) {
+	role := testutil.NewRole("ns", "compute").Build()
+	role.Spec.NodeTemplate.ContainerImages = map[string]string{"edpm_nova_compute_image": "nova:role"}
+	defaults := &corev1beta1.OpenStackDataPlaneDefaultNodeTemplate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: corev1beta1.DefaultNodeTemplateName},
+		Spec: corev1beta1.OpenStackDataPlaneDefaultNodeTemplateSpec{
+			NodeTemplate: corev1beta1.NodeSection{ContainerImages: map[string]string{
+				"edpm_nova_compute_image":   "nova:default",
+				"edpm_ovn_controller_image": "ovn:default",
+			}},
+		},
+	}
+	nodes := []corev1beta1.OpenStackDataPlaneNode{
+		*testutil.NewNode("ns", "compute-0").Build(),
+		*testutil.NewNode("ns", "compute-1").Build(),
+		*testutil.NewNode("ns", "compute-2").Build(),
+	}
+	// Pins the images the node gets anyway
+	nodes[0].Spec.Node.ContainerImages = map[string]string{
+		"edpm_nova_compute_image":   "nova:role",
+		"edpm_ovn_controller_image": "ovn:default",
+	}
+	// Canary node
+	nodes[1].Spec.Node.ContainerImages = map[string]string{
+		"edpm_ovn_controller_image": "ovn:canary",
+		"edpm_nova_compute_image":   "nova:canary",
+	}
+	// Pins the default the role overrides
+	nodes[2].Spec.Node.ContainerImages = map[string]string{"edpm_nova_compute_image": "nova:default"}
+
+	scheme, err := testutil.NewScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &OpenStackDataPlaneRoleReconciler{Client: testutil.NewFakeClient(scheme, defaults), Scheme: scheme}
+	template, err := r.GetEffectiveTemplate(context.Background(), role)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"compute-1: edpm_nova_compute_image=nova:canary",
+		"compute-1: edpm_ovn_controller_image=ovn:canary",
+		"compute-2: edpm_nova_compute_image=nova:default",
+	}
+	if got := imageOverrides(template, nodes); !reflect.DeepEqual(got, expected) {
+		t.Errorf("image overrides = %v, want %v", got, expected)
+	}
+}
+
+func TestGenerateInventoryDynamic(t *testing.T) {
+	ctx := context.Background()
+	role := testutil.NewRole("ns", "compute").Build()
+	nodes := []corev1beta1.OpenStackDataPlaneNode{
+		*testutil.NewNode("ns", "compute-0").WithRole("compute").WithNetwork("ctlplane", "192.168.122.100").Build(),
+		*testutil.NewNode("ns", "compute-1").WithRole("compute").WithNetwork("ctlplane", "192.168.122.101").Build(),
+		*testutil.NewNode("ns", "compute-2").WithRole("compute").WithNetwork("ctlplane", "192.168.122.102").InMaintenance().Build(),
+	}
+	var objs []client.Object
+	for _, name := range []string{"compute-0", "compute-2"} {
+		objs = append(objs, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "dataplanenode-" + name + "-inventory"},
+			Data:       map[string]string{"inventory": "all:\n  hosts:\n    " + name + ":\n      ansible_host: " + name + "\n"},
+		})
+	}
+	scheme, err := testutil.NewScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &OpenStackDataPlaneRoleReconciler{Client: testutil.NewFakeClient(scheme, objs...), Scheme: scheme}
+	settings, err := r.GetEffectiveNodes(ctx, role, nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.GenerateInventory(ctx, role, nodes, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cm := &corev1.ConfigMap{}
+	err = r.Client.Get(ctx, client.ObjectKey{Namespace: "ns", Name: "dataplanerole-compute-inventory"}, cm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var inventory struct {
+		All struct {
+			Children []string `json:"children"`
+		} `json:"all"`
+		Compute struct {
+			Hosts []string `json:"hosts"`
+			Vars  struct {
+				Peers map[string][]map[string]string `json:"edpm_role_peers"`
+			} `json:"vars"`
+		} `json:"compute"`
+		Meta struct {
+			HostVars map[string]map[string]interface{} `json:"hostvars"`
+		} `json:"_meta"`
+	}
+	err = json.Unmarshal([]byte(cm.Data["inventory.json"]), &inventory)
+	if err != nil {
+		t.Fatalf("inventory.json is not JSON: %v\n%s", err, cm.Data["inventory.json"])
+	}
+
+	if want := []string{"compute"}; !reflect.DeepEqual(inventory.All.Children, want) {
+		t.Errorf("all.children = %v, want %v", inventory.All.Children, want)
+	}
+	if want := []string{"compute-0", "compute-1"}; !reflect.DeepEqual(inventory.Compute.Hosts, want) {
+		t.Errorf("hosts = %v, want %v", inventory.Compute.Hosts, want)
+	}
+	var peers []string
+	for _, peer := range inventory.Compute.Vars.Peers["ctlplane"] {
+		peers = append(peers, peer["name"])
+	}
+	if want := []string{"compute-0", "compute-1"}; !reflect.DeepEqual(peers, want) {
+		t.Errorf("edpm_role_peers = %v, want %v", peers, want)
+	}
+	expected := map[string]map[string]interface{}{
+		"compute-0": {"ansible_host": "compute-0"},
+		"compute-1": {},
+	}
+	if !reflect.DeepEqual(inventory.Meta.HostVars, expected) {
+		t.Errorf("_meta.hostvars = %v, want %v", inventory.Meta.HostVars, expected)
+	}
+}
@@ -19,6 +19,7 @@ package main
 import (
 	"flag"
 	"os"
+	"strings"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -33,6 +34,7 @@ import (
 
 	corev1beta1 "github.com/openstack-k8s-operators/dataplane-operator/api/v1beta1"
 	"github.com/openstack-k8s-operators/dataplane-operator/controllers"
+	"github.com/openstack-k8s-operators/dataplane-operator/pkg/faultinject"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -52,11 +54,15 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var faultInjectionRate float64
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.Float64Var(&faultInjectionRate, "fault-injection-rate", 0,
+		"Fraction of API calls of the controllers to fail with a transient error. "+
+			"Only effective in builds with the faultinject build tag, for development.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -89,27 +95,47 @@ func main() {
 		os.Exit(1)
 	}
 
+	if faultInjectionRate > 0 && !faultinject.Enabled {
+		setupLog.Info("fault-injection-rate is ignored, the operator was built without the faultinject build tag")
+	}
+	controllerClient := faultinject.Wrap(mgr.GetClient(), faultInjectionRate)
+
 	if err = (&controllers.OpenStackDataPlaneReconciler{
-		Client: mgr.GetClient(),
+		Client: controllerClient,
 		Scheme: mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "OpenStackDataPlane")
 		os.Exit(1)
 	}
 	if err = (&controllers.OpenStackDataPlaneRoleReconciler{
-		Client: mgr.GetClient(),
+		Client: controllerClient,
 		Scheme: mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "OpenStackDataPlaneRole")
 		os.Exit(1)
 	}
 	if err = (&controllers.OpenStackDataPlaneNodeReconciler{
-		Client: mgr.GetClient(),
+		Client: controllerClient,
 		Scheme: mgr.GetScheme(),
+		Log:    ctrl.Log.WithName("controllers").WithName("OpenStackDataPlaneNode"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "OpenStackDataPlaneNode")
 		os.Exit(1)
 	}
+	if strings.ToLower(os.Getenv("ENABLE_WEBHOOKS")) != "false" {
+		if err = (&corev1beta1.OpenStackDataPlaneNode{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "OpenStackDataPlaneNode")
+			os.Exit(1)
+		}
+		if err = (&corev1beta1.OpenStackDataPlaneRole{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "OpenStackDataPlaneRole")
+			os.Exit(1)
+		}
+		if err = (&corev1beta1.OpenStackDataPlaneDefaultNodeTemplate{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "OpenStackDataPlaneDefaultNodeTemplate")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -0,0 +1,31 @@
+//go:build !faultinject
+
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package faultinject
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Enabled reports whether fault injection is compiled in
+const Enabled = false
+
+// Wrap returns c unchanged, fault injection is not compiled in
+func Wrap(c client.Client, rate float64) client.Client {
+	return c
+}
@@ -0,0 +1,32 @@
+//go:build !faultinject
+
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package faultinject
+
+import (
+	"testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWrapDisabled(t *testing.T) {
+	c := fake.NewClientBuilder().Build()
+	if Wrap(c, 1) != c {
+		t.Error("expected the client to be returned unchanged without the faultinject tag")
+	}
+}
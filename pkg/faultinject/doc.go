@@ -0,0 +1,24 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package faultinject wraps the controller client so that a fraction of the
+// API calls fail, to exercise the error handling paths of the reconcilers.
+//
+// Fault injection is only compiled in when building with the faultinject
+// build tag, e.g. "go run -tags faultinject ./main.go
+// --fault-injection-rate=0.1". Without the tag, Wrap returns the client
+// unchanged and the operator behaves as usual whatever the rate.
+package faultinject
@@ -0,0 +1,146 @@
+//go:build faultinject
+
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package faultinject
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var faultlog = logf.Log.WithName("faultinject")
+
+// Enabled reports whether fault injection is compiled in
+const Enabled = true
+
+// Wrap returns a client failing the given fraction (0 to 1) of its calls
+// with a random transient API error
+func Wrap(c client.Client, rate float64) client.Client {
+	if rate <= 0 {
+		return c
+	}
+	return &faultyClient{
+		Client: c,
+		rate:   rate,
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+type faultyClient struct {
+	client.Client
+	rate float64
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// fault returns an error for the call when it was picked to fail
+func (c *faultyClient) fault(verb string, obj interface{}, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.rand.Float64() >= c.rate {
+		return nil
+	}
+
+	gr := schema.GroupResource{Resource: fmt.Sprintf("%T", obj)}
+	var err error
+	switch c.rand.Intn(3) {
+	case 0:
+		err = k8s_errors.NewConflict(gr, name, fmt.Errorf("injected fault"))
+	case 1:
+		err = k8s_errors.NewServiceUnavailable("injected fault")
+	default:
+		err = k8s_errors.NewTimeoutError("injected fault", 1)
+	}
+	faultlog.Info("injecting fault", "verb", verb, "resource", gr.Resource, "name", name, "error", err.Error())
+	return err
+}
+
+func (c *faultyClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if err := c.fault("get", obj, key.Name); err != nil {
+		return err
+	}
+	return c.Client.Get(ctx, key, obj)
+}
+
+func (c *faultyClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if err := c.fault("list", list, ""); err != nil {
+		return err
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+func (c *faultyClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if err := c.fault("create", obj, obj.GetName()); err != nil {
+		return err
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *faultyClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if err := c.fault("update", obj, obj.GetName()); err != nil {
+		return err
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *faultyClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if err := c.fault("patch", obj, obj.GetName()); err != nil {
+		return err
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *faultyClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if err := c.fault("delete", obj, obj.GetName()); err != nil {
+		return err
+	}
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func (c *faultyClient) Status() client.StatusWriter {
+	return &faultyStatusWriter{StatusWriter: c.Client.Status(), client: c}
+}
+
+type faultyStatusWriter struct {
+	client.StatusWriter
+	client *faultyClient
+}
+
+func (w *faultyStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if err := w.client.fault("update status", obj, obj.GetName()); err != nil {
+		return err
+	}
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+func (w *faultyStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if err := w.client.fault("patch status", obj, obj.GetName()); err != nil {
+		return err
+	}
+	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+}
@@ -0,0 +1,75 @@
+//go:build faultinject
+
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package faultinject
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newFakeClient() client.Client {
+	return fake.NewClientBuilder().WithObjects(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "inventory"},
+	}).Build()
+}
+
+func TestWrapRateZero(t *testing.T) {
+	c := newFakeClient()
+	if Wrap(c, 0) != c {
+		t.Error("expected a rate of 0 to return the client unchanged")
+	}
+}
+
+func TestWrapRateOne(t *testing.T) {
+	ctx := context.Background()
+	c := Wrap(newFakeClient(), 1)
+	key := client.ObjectKey{Namespace: "ns", Name: "inventory"}
+
+	conflicts := 0
+	for i := 0; i < 100; i++ {
+		err := c.Get(ctx, key, &corev1.ConfigMap{})
+		switch {
+		case err == nil:
+			t.Fatal("expected every call to fail with a rate of 1")
+		case k8s_errors.IsConflict(err):
+			conflicts++
+			var statusErr *k8s_errors.StatusError
+			if !errors.As(err, &statusErr) || statusErr.ErrStatus.Details.Name != key.Name {
+				t.Errorf("expected the conflict to name %q, got %v", key.Name, err)
+			}
+		case k8s_errors.IsServiceUnavailable(err), k8s_errors.IsTimeout(err):
+		default:
+			t.Errorf("unexpected injected error %v", err)
+		}
+	}
+	if conflicts == 0 {
+		t.Error("expected some of the injected errors to be conflicts")
+	}
+
+	if err := c.Status().Update(ctx, &corev1.ConfigMap{}); err == nil {
+		t.Error("expected status updates to fail with a rate of 1")
+	}
+}
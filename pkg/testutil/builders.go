@@ -0,0 +1,229 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testutil
+
+import (
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	corev1beta1 "github.com/openstack-k8s-operators/dataplane-operator/api/v1beta1"
+)
+
+// NodeBuilder builds an OpenStackDataPlaneNode
+type NodeBuilder struct {
+	node *corev1beta1.OpenStackDataPlaneNode
+}
+
+// NewNode returns a NodeBuilder for a managed node whose host name is name,
+// reachable as cloud-admin through the ctlplane network
+func NewNode(namespace string, name string) *NodeBuilder {
+	return &NodeBuilder{
+		node: &corev1beta1.OpenStackDataPlaneNode{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: corev1beta1.OpenStackDataPlaneNodeSpec{
+				Node: corev1beta1.NodeSection{
+					HostName:          name,
+					Managed:           true,
+					ManagementNetwork: "ctlplane",
+					AnsibleUser:       "cloud-admin",
+					AnsiblePort:       22,
+				},
+			},
+		},
+	}
+}
+
+// WithRole sets the role the node belongs to
+func (b *NodeBuilder) WithRole(role string) *NodeBuilder {
+	b.node.Spec.Role = role
+	return b
+}
+
+// WithAnsibleHost sets the address ansible connects to
+func (b *NodeBuilder) WithAnsibleHost(host string) *NodeBuilder {
+	b.node.Spec.Node.AnsibleHost = host
+	return b
+}
+
+// WithNetwork attaches the node to network with a fixed IP
+func (b *NodeBuilder) WithNetwork(network string, fixedIP string) *NodeBuilder {
+	b.node.Spec.Node.Networks = append(b.node.Spec.Node.Networks, corev1beta1.NetworksSection{
+		Network: network,
+		FixedIP: fixedIP,
+	})
+	return b
+}
+
+// WithPasswordSecret sets the Secret holding the ansible password
+func (b *NodeBuilder) WithPasswordSecret(secret string) *NodeBuilder {
+	b.node.Spec.Node.AnsiblePasswordSecret = secret
+	return b
+}
+
+// WithPhysnet maps physnet to bridge on the node
+func (b *NodeBuilder) WithPhysnet(physnet string, bridge string, vlanRanges ...string) *NodeBuilder {
+	b.node.Spec.Node.Physnets = append(b.node.Spec.Node.Physnets, corev1beta1.PhysnetSection{
+		Name:       physnet,
+		Bridge:     bridge,
+		VLANRanges: vlanRanges,
+	})
+	return b
+}
+
+// InMaintenance puts the node in maintenance
+func (b *NodeBuilder) InMaintenance() *NodeBuilder {
+	b.node.Spec.Node.Maintenance = true
+	return b
+}
+
+// Unmanaged marks the node as not provisioned by the operator
+func (b *NodeBuilder) Unmanaged() *NodeBuilder {
+	b.node.Spec.Node.Managed = false
+	return b
+}
+
+// WithCondition sets a condition in the node status, for tests of consumers
+// of the status that do not run the node controller
+func (b *NodeBuilder) WithCondition(conditionType string, status metav1.ConditionStatus, reason string) *NodeBuilder {
+	b.node.Status.Conditions = append(b.node.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		LastTransitionTime: metav1.Now(),
+	})
+	return b
+}
+
+// Build returns the node
+func (b *NodeBuilder) Build() *corev1beta1.OpenStackDataPlaneNode {
+	return b.node.DeepCopy()
+}
+
+// RoleBuilder builds an OpenStackDataPlaneRole
+type RoleBuilder struct {
+	role *corev1beta1.OpenStackDataPlaneRole
+}
+
+// NewRole returns a RoleBuilder for a role whose node template manages the
+// nodes through the ctlplane network as cloud-admin
+func NewRole(namespace string, name string) *RoleBuilder {
+	return &RoleBuilder{
+		role: &corev1beta1.OpenStackDataPlaneRole{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: corev1beta1.OpenStackDataPlaneRoleSpec{
+				NodeTemplate: corev1beta1.NodeSection{
+					Managed:           true,
+					ManagementNetwork: "ctlplane",
+					AnsibleUser:       "cloud-admin",
+					AnsiblePort:       22,
+				},
+			},
+		},
+	}
+}
+
+// WithNodes lists nodes in the role, referencing OpenStackDataPlaneNodes by name
+func (b *RoleBuilder) WithNodes(nodes ...string) *RoleBuilder {
+	for _, node := range nodes {
+		b.role.Spec.DataPlaneNodes = append(b.role.Spec.DataPlaneNodes, corev1beta1.DataPlaneNodeSection{
+			NodeFrom: node,
+		})
+	}
+	return b
+}
+
+// WithGlobalVars references OpenStackDataPlaneGlobalVars from the role
+func (b *RoleBuilder) WithGlobalVars(globalVars ...string) *RoleBuilder {
+	b.role.Spec.GlobalVars = append(b.role.Spec.GlobalVars, globalVars...)
+	return b
+}
+
+// WithReadinessPolicy sets the conditions the role requires to be ready
+func (b *RoleBuilder) WithReadinessPolicy(requiredConditions ...string) *RoleBuilder {
+	b.role.Spec.ReadinessPolicy.RequiredConditions = requiredConditions
+	return b
+}
+
+// ManagingEtcHosts enables the /etc/hosts fragment of the role
+func (b *RoleBuilder) ManagingEtcHosts() *RoleBuilder {
+	b.role.Spec.ManageEtcHosts = true
+	return b
+}
+
+// Build returns the role
+func (b *RoleBuilder) Build() *corev1beta1.OpenStackDataPlaneRole {
+	return b.role.DeepCopy()
+}
+
+// NewDataPlane returns an OpenStackDataPlane holding the specs of roles
+func NewDataPlane(namespace string, name string, roles ...*corev1beta1.OpenStackDataPlaneRole) *corev1beta1.OpenStackDataPlane {
+	dataplane := &corev1beta1.OpenStackDataPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+	for _, role := range roles {
+		dataplane.Spec.DataPlaneRoles = append(dataplane.Spec.DataPlaneRoles, role.Spec)
+	}
+	return dataplane
+}
+
+// NewGlobalVars returns an OpenStackDataPlaneGlobalVars holding vars, which
+// may be any value that can be marshalled to JSON
+func NewGlobalVars(namespace string, name string, vars map[string]interface{}) (*corev1beta1.OpenStackDataPlaneGlobalVars, error) {
+	globalVars := &corev1beta1.OpenStackDataPlaneGlobalVars{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: corev1beta1.OpenStackDataPlaneGlobalVarsSpec{
+			Vars: make(map[string]apiextensionsv1.JSON, len(vars)),
+		},
+	}
+	for key, value := range vars {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		globalVars.Spec.Vars[key] = apiextensionsv1.JSON{Raw: raw}
+	}
+	return globalVars, nil
+}
+
+// NewAnsiblePasswordSecret returns a Secret holding an ansible password in
+// the format expected by ansiblePasswordSecret
+func NewAnsiblePasswordSecret(namespace string, name string, password string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			"password": []byte(password),
+		},
+	}
+}
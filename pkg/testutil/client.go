@@ -0,0 +1,62 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testutil
+
+import (
+	"context"
+
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// NewFakeClient returns a controller-runtime fake client holding objs. The
+// fake client does not implement server-side apply, so apply patches are
+// emulated by creating the object or replacing the existing one. Field
+// ownership is not tracked, tests relying on it need envtest.
+func NewFakeClient(scheme *k8sruntime.Scheme, objs ...client.Object) client.Client {
+	return &applyClient{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+	}
+}
+
+// applyClient emulates apply patches on top of a fake client
+type applyClient struct {
+	client.Client
+}
+
+func (c *applyClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch.Type() != types.ApplyPatchType {
+		return c.Client.Patch(ctx, obj, patch, opts...)
+	}
+
+	existing, ok := obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return c.Client.Patch(ctx, obj, patch, opts...)
+	}
+	err := c.Client.Get(ctx, client.ObjectKeyFromObject(obj), existing)
+	if k8s_errors.IsNotFound(err) {
+		return c.Client.Create(ctx, obj)
+	}
+	if err != nil {
+		return err
+	}
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	return c.Client.Update(ctx, obj)
+}
@@ -0,0 +1,26 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package testutil provides fixtures for tests running against the
+// dataplane-operator API, either with envtest or with NewFakeClient. The
+// reconcilers write with server-side apply, which the controller-runtime fake
+// client does not support; NewFakeClient emulates it without field ownership.
+//
+// It is meant for this operator as well as for other operators integrating
+// with the dataplane: builders create realistic OpenStackDataPlane, Role,
+// Node and GlobalVars objects, and the helpers set up a scheme, locate the
+// CRDs for envtest and drive reconciles until they settle.
+package testutil
@@ -0,0 +1,94 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	corev1beta1 "github.com/openstack-k8s-operators/dataplane-operator/api/v1beta1"
+)
+
+// MaxReconciles bounds the number of reconciles ReconcileUntilDone runs
+// before giving up on a reconciler that keeps requeueing
+const MaxReconciles = 10
+
+// NewScheme returns a scheme with the Kubernetes and dataplane API types
+func NewScheme() (*k8sruntime.Scheme, error) {
+	scheme := k8sruntime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := corev1beta1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	return scheme, nil
+}
+
+// CRDDirectoryPaths returns the directories holding the dataplane CRDs, to be
+// used as envtest.Environment CRDDirectoryPaths. It resolves the path from
+// the location of this package, so it also works from the module cache of
+// other operators.
+func CRDDirectoryPaths() []string {
+	_, file, _, _ := runtime.Caller(0)
+	return []string{filepath.Join(filepath.Dir(file), "..", "..", "config", "crd", "bases")}
+}
+
+// Reconcile runs a single reconcile of obj
+func Reconcile(ctx context.Context, r reconcile.Reconciler, obj client.Object) (ctrl.Result, error) {
+	return r.Reconcile(ctx, ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Namespace: obj.GetNamespace(),
+			Name:      obj.GetName(),
+		},
+	})
+}
+
+// ReconcileUntilDone reconciles obj until the reconciler stops requeueing,
+// returning the first error. As tests do not have the watches of the
+// manager, reconcilers depending on each other need to be driven in turn.
+func ReconcileUntilDone(ctx context.Context, r reconcile.Reconciler, obj client.Object) error {
+	for i := 0; i < MaxReconciles; i++ {
+		result, err := Reconcile(ctx, r, obj)
+		if err != nil {
+			return err
+		}
+		if !result.Requeue && result.RequeueAfter == 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s/%s still requeued after %d reconciles", obj.GetNamespace(), obj.GetName(), MaxReconciles)
+}
+
+// CreateAll creates objs in order, stopping at the first error
+func CreateAll(ctx context.Context, c client.Client, objs ...client.Object) error {
+	for _, obj := range objs {
+		if err := c.Create(ctx, obj); err != nil {
+			return fmt.Errorf("creating %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testutil_test
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	corev1beta1 "github.com/openstack-k8s-operators/dataplane-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/dataplane-operator/pkg/testutil"
+)
+
+func TestNewScheme(t *testing.T) {
+	scheme, err := testutil.NewScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, gvk := range []schema.GroupVersionKind{
+		corev1beta1.GroupVersion.WithKind("OpenStackDataPlaneNode"),
+		corev1beta1.GroupVersion.WithKind("OpenStackDataPlaneRole"),
+		corev1beta1.GroupVersion.WithKind("OpenStackDataPlaneGlobalVars"),
+		corev1.SchemeGroupVersion.WithKind("Secret"),
+	} {
+		if !scheme.Recognizes(gvk) {
+			t.Errorf("scheme does not recognize %s", gvk)
+		}
+	}
+}
+
+func TestNodeBuilder(t *testing.T) {
+	node := testutil.NewNode("ns", "compute-0").
+		WithRole("compute").
+		WithNetwork("ctlplane", "192.168.122.100").
+		WithPhysnet("datacentre", "br-ex", "1:100").
+		InMaintenance().
+		Build()
+
+	if node.Namespace != "ns" || node.Name != "compute-0" || node.Spec.Node.HostName != "compute-0" {
+		t.Errorf("unexpected identity %s/%s, host name %q", node.Namespace, node.Name, node.Spec.Node.HostName)
+	}
+	if node.Spec.Role != "compute" {
+		t.Errorf("role = %q, want compute", node.Spec.Role)
+	}
+	if len(node.Spec.Node.Networks) != 1 || node.Spec.Node.Networks[0].FixedIP != "192.168.122.100" {
+		t.Errorf("unexpected networks %v", node.Spec.Node.Networks)
+	}
+	if len(node.Spec.Node.Physnets) != 1 || node.Spec.Node.Physnets[0].Bridge != "br-ex" {
+		t.Errorf("unexpected physnets %v", node.Spec.Node.Physnets)
+	}
+	if !node.Spec.Node.Maintenance {
+		t.Error("node is not in maintenance")
+	}
+}
+
+func TestNewGlobalVars(t *testing.T) {
+	globalVars, err := testutil.NewGlobalVars("ns", "vars", map[string]interface{}{
+		"timesync_ntp_servers": []string{"pool.ntp.org"},
+		"edpm_sshd_enabled":    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(globalVars.Spec.Vars["timesync_ntp_servers"].Raw); got != `["pool.ntp.org"]` {
+		t.Errorf("timesync_ntp_servers = %s", got)
+	}
+	if got := string(globalVars.Spec.Vars["edpm_sshd_enabled"].Raw); got != "true" {
+		t.Errorf("edpm_sshd_enabled = %s", got)
+	}
+}
+
+func TestNewFakeClientApply(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := testutil.NewScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := testutil.NewFakeClient(scheme)
+
+	apply := func(value string) {
+		t.Helper()
+		cm := &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "inventory"},
+			Data:       map[string]string{"inventory": value},
+		}
+		if err := c.Patch(ctx, cm, client.Apply, client.FieldOwner("test")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	apply("first")
+	apply("second")
+
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: "ns", Name: "inventory"}, cm); err != nil {
+		t.Fatal(err)
+	}
+	if cm.Data["inventory"] != "second" {
+		t.Errorf("inventory = %q, want second", cm.Data["inventory"])
+	}
+}
+
+func TestCreateAllAndReconcileUntilDone(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := testutil.NewScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := testutil.NewFakeClient(scheme)
+	role := testutil.NewRole("ns", "compute").Build()
+	node := testutil.NewNode("ns", "compute-0").WithRole("compute").Build()
+	if err := testutil.CreateAll(ctx, c, role, node); err != nil {
+		t.Fatal(err)
+	}
+
+	reconciles := 0
+	requeueTwice := reconcile.Func(func(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+		reconciles++
+		if err := c.Get(ctx, req.NamespacedName, &corev1beta1.OpenStackDataPlaneNode{}); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: reconciles < 3}, nil
+	})
+	if err := testutil.ReconcileUntilDone(ctx, requeueTwice, node); err != nil {
+		t.Fatal(err)
+	}
+	if reconciles != 3 {
+		t.Errorf("reconciled %d times, want 3", reconciles)
+	}
+
+	alwaysRequeue := reconcile.Func(func(context.Context, ctrl.Request) (ctrl.Result, error) {
+		return ctrl.Result{Requeue: true}, nil
+	})
+	if err := testutil.ReconcileUntilDone(ctx, alwaysRequeue, node); err == nil {
+		t.Error("expected an error for a reconciler that keeps requeueing")
+	}
+}